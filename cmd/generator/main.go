@@ -15,6 +15,8 @@ import (
 	"github.com/segmentio/kafka-go"
 
 	"order-service/internal/domain/models"
+	orderkafka "order-service/internal/infrastructure/kafka"
+	"order-service/internal/tracing"
 )
 
 func main() {
@@ -57,6 +59,21 @@ func main() {
 		PrintOnly:    *printOnly,
 	}
 
+	// Контекст трассировки передается в заголовках, и span'ы потребителя продолжают span отправки
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    os.Getenv("TRACING_EXPORTER"),
+		FilePath:    os.Getenv("TRACING_FILE"),
+		ServiceName: "order-generator",
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	var writer *kafka.Writer
 	if !config.PrintOnly {
 		writer = &kafka.Writer{
@@ -87,12 +104,13 @@ func main() {
 		if config.PrintOnly {
 			fmt.Printf("Order %d: %s\n", i+1, string(orderJSON))
 		} else {
-			err = writer.WriteMessages(context.Background(),
-				kafka.Message{
-					Key:   []byte(order.OrderUID),
-					Value: orderJSON,
-				},
-			)
+			msg := kafka.Message{
+				Key:   []byte(order.OrderUID),
+				Value: orderJSON,
+			}
+			ctx, span := orderkafka.StartProducerSpan(context.Background(), config.KafkaTopic, &msg)
+			err = writer.WriteMessages(ctx, msg)
+			tracing.End(span, err)
 			if err != nil {
 				log.Printf("Error sending message to Kafka: %v", err)
 			} else {
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/auth"
+	"order-service/internal/infrastructure/cache"
+	"order-service/internal/infrastructure/events"
+	"order-service/internal/infrastructure/grpc"
+	"order-service/internal/infrastructure/http"
+	"order-service/internal/infrastructure/kafka"
+	"order-service/internal/infrastructure/postgres"
+	"order-service/internal/usecase"
+	"order-service/pkg/interfaces"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Имена компонентов приложения
+const (
+	componentPostgres              = "postgres"
+	componentCache                 = "cache"
+	componentCacheRefresh          = "cache_refresh"
+	componentInvalidationPublisher = "invalidation_publisher"
+	componentInvalidationConsumer  = "invalidation_consumer"
+	componentOrderConsumer         = "order_consumer"
+	componentHTTP                  = "http"
+	componentGRPC                  = "grpc"
+	componentCacheWarmer           = "cache_warmer"
+	componentConfigWatcher         = "config_watcher"
+)
+
+// newApplication собирает компоненты сервиса по конфигурации. Подключения к PostgreSQL, Redis
+// и Kafka устанавливаются при запуске приложения, а не здесь.
+func newApplication(cfg *config.Config, reloader *config.Reloader) (*usecase.Application, error) {
+	app := usecase.NewApplication(cfg.ShutdownComponentTimeout)
+
+	// Пул соединений создается сразу, а готовность PostgreSQL проверяется при запуске
+	connectOpts := newConnectOptions(cfg)
+	db, err := postgres.OpenDB(cfg.GetDBConnString(), connectOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	app.Add(usecase.Component{
+		Name: componentPostgres,
+		// Ждем готовности PostgreSQL не дольше db.connect_max_wait
+		Start: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, cfg.DBPool.ConnectMaxWait)
+			defer cancel()
+			return postgres.WaitReady(ctx, db, connectOpts)
+		},
+		Stop: func(context.Context) error { return db.Close() },
+	})
+	repo := postgres.NewPostgresRepository(db)
+
+	// Инициализация кеша
+	backend, err := newCacheRepository(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize %s cache: %w", cfg.CacheBackend, err)
+	}
+	app.Add(usecase.Component{
+		Name:  componentCache,
+		Start: backend.start,
+		Stop: func(context.Context) error {
+			backend.close()
+			return nil
+		},
+	})
+	reloader.Subscribe("cache", backend.apply)
+	cacheRepo, localCache := backend.repo, backend.local
+
+	// TTL элемента зависит от возраста заказа; популярные ключи перезагружаются до истечения TTL
+	ttlPolicy := newTTLPolicy(cfg)
+	refreshTTL := models.NewTTLPolicyVar(ttlPolicy)
+	if cfg.CacheRefreshAheadWindow > 0 {
+		refreshAhead := cache.NewRefreshAheadCache(
+			cacheRepo,
+			usecase.NewOrderCacheLoader(repo, refreshTTL),
+			cfg.CacheRefreshAheadWindow,
+			5*time.Second,
+		)
+		// Идущие перезагрузки завершаются до закрытия кеша и БД
+		app.Add(usecase.Component{
+			Name:      componentCacheRefresh,
+			DependsOn: []string{componentPostgres, componentCache},
+			Stop: func(context.Context) error {
+				refreshAhead.Wait()
+				return nil
+			},
+		})
+		cacheRepo = refreshAhead
+	}
+
+	// Прогрев кеша из БД
+	warmer := usecase.NewCacheWarmer(repo, cacheRepo, usecase.WarmupOptions{
+		BatchSize:   cfg.WarmupBatchSize,
+		MaxAge:      cfg.WarmupMaxAge,
+		MaxOrders:   cfg.WarmupMaxOrders,
+		Concurrency: cfg.WarmupConcurrency,
+		TTL:         ttlPolicy,
+	})
+
+	// Шина событий о сохраненных заказах
+	eventBus := events.NewBroker(cfg.OrderEventsHistory)
+
+	// Прием и чтение заказов для Kafka, HTTP и gRPC
+	orders := usecase.NewOrderService(repo, cacheRepo)
+	orders.SetTTLPolicy(ttlPolicy)
+	orders.SetEventBus(eventBus)
+
+	// Аутентификация по ключам и JWT; nil, если ничего не настроено
+	authenticator, err := newAuthenticator(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize authentication: %w", err)
+	}
+	routeRoles, err := auth.ParseRouteRoles(cfg.AuthRouteRoles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_ROUTE_ROLES: %w", err)
+	}
+
+	piiPolicy, err := newPIIPolicy(cfg.PIIPrivilegedRoles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PII_PRIVILEGED_ROLES: %w", err)
+	}
+
+	rateLimits, err := newRateLimitConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+
+	// HTTP-сервер с эндпоинтами администрирования кеша
+	httpServer := http.NewOrderHTTPServer(cfg.ServerPort, orders, cacheRepo)
+	if authenticator != nil {
+		httpServer.SetAuthenticator(authenticator)
+	}
+	httpServer.SetRouteRoles(routeRoles)
+	httpServer.SetPIIPolicy(piiPolicy)
+	httpServer.SetRateLimits(rateLimits)
+	httpServer.EnableAdmin(warmer)
+	httpServer.SetEventBus(eventBus)
+	httpServer.SetCacheMaxAge(cfg.HTTPCacheMaxAge)
+	httpServer.SetFeatures(newFeatures(cfg))
+	httpServer.SetConfigReloader(reloader)
+	httpServer.SetTimeouts(http.Timeouts{
+		Read:       cfg.HTTPTimeouts.Read,
+		ReadHeader: cfg.HTTPTimeouts.ReadHeader,
+		Write:      cfg.HTTPTimeouts.Write,
+		Idle:       cfg.HTTPTimeouts.Idle,
+	})
+
+	consumer := kafka.NewOrderKafkaConsumer(
+		cfg.KafkaBrokers,
+		cfg.KafkaTopic,
+		cfg.KafkaGroupID,
+		orders,
+	)
+	consumer.SetTimeouts(kafka.ConsumerTimeouts{
+		Fetch: cfg.Consumer.FetchTimeout,
+		Save:  cfg.Consumer.SaveTimeout,
+	})
+
+	reloader.Subscribe("ttl_policy", func(cfg *config.Config) error {
+		policy := newTTLPolicy(cfg)
+		refreshTTL.Store(policy)
+		orders.SetTTLPolicy(policy)
+		warmer.SetTTLPolicy(policy)
+		return nil
+	})
+	reloader.Subscribe("http", func(cfg *config.Config) error {
+		rateLimits, err := newRateLimitConfig(cfg)
+		if err != nil {
+			return err
+		}
+		httpServer.SetRateLimits(rateLimits)
+		httpServer.SetFeatures(newFeatures(cfg))
+		return nil
+	})
+
+	// Заказы и запросы к ним обслуживаются, только когда доступны БД и кеш
+	dataDependencies := []string{componentPostgres, componentCache}
+	// Потребители и фоновые задачи перезапускаются после отказа, отказ серверов завершает сервис
+	restart := newRestartPolicy(cfg)
+
+	// Инвалидации кеша между репликами: локальные копии устаревают при корректировке заказа
+	if cfg.CacheInvalidationEnabled && localCache != nil {
+		publisher := kafka.NewInvalidationPublisher(cfg.KafkaBrokers, cfg.CacheInvalidationTopic, cfg.InstanceID)
+		app.Add(usecase.Component{
+			Name: componentInvalidationPublisher,
+			Stop: func(context.Context) error { return publisher.Close() },
+		})
+		orders.SetInvalidationPublisher(publisher)
+		httpServer.SetInvalidationPublisher(publisher)
+		dataDependencies = append(dataDependencies, componentInvalidationPublisher)
+
+		subscriber := kafka.NewInvalidationSubscriber(
+			cfg.KafkaBrokers,
+			cfg.CacheInvalidationTopic,
+			cfg.CacheInvalidationGroupPrefix,
+			cfg.InstanceID,
+			localCache,
+		)
+		invalidationConsumer := usecase.ConsumerComponent(componentInvalidationConsumer, subscriber, componentCache)
+		invalidationConsumer.Restart = restart
+		app.Add(invalidationConsumer)
+	}
+
+	orderConsumer := usecase.ConsumerComponent(componentOrderConsumer, consumer, dataDependencies...)
+	orderConsumer.Restart = restart
+	app.Add(orderConsumer, usecase.ServerComponent(componentHTTP, httpServer, dataDependencies...))
+
+	if cfg.GRPCPort > 0 {
+		grpcServer := grpc.NewOrderGRPCServer(cfg.GRPCPort, orders, eventBus)
+		if authenticator != nil {
+			grpcServer.SetAuthenticator(authenticator)
+		}
+		grpcServer.SetPIIPolicy(piiPolicy)
+		app.Add(usecase.ServerComponent(componentGRPC, grpcServer, dataDependencies...))
+	}
+
+	// Прогреваем кеш в фоне, не блокируя запуск серверов
+	cacheWarmer := usecase.BackgroundJob(componentCacheWarmer, func(ctx context.Context) error {
+		if err := warmer.Run(ctx); err != nil {
+			slog.Warn("Continuing with partially populated cache", "error", err)
+		}
+		return nil
+	}, componentPostgres, componentCache)
+	configWatcher := usecase.BackgroundJob(componentConfigWatcher, func(ctx context.Context) error {
+		reloader.Watch(ctx, cfg.ReloadWatchInterval)
+		return nil
+	})
+	configWatcher.Restart = restart
+	app.Add(cacheWarmer, configWatcher)
+
+	return app, nil
+}
+
+// newRestartPolicy переносит параметры перезапуска отказавших компонентов из конфигурации
+func newRestartPolicy(cfg *config.Config) usecase.RestartPolicy {
+	return usecase.RestartPolicy{
+		MaxRestarts: cfg.Restart.MaxAttempts,
+		Backoff:     cfg.Restart.Backoff,
+		MaxBackoff:  cfg.Restart.MaxBackoff,
+	}
+}
+
+// newConnectOptions переносит параметры пула из конфигурации
+func newConnectOptions(cfg *config.Config) postgres.ConnectOptions {
+	opts := postgres.DefaultConnectOptions()
+	opts.MaxOpenConns = cfg.DBPool.MaxConns
+	opts.MaxIdleConns = cfg.DBPool.IdleConns
+	opts.ConnMaxLifetime = cfg.DBPool.ConnMaxLifetime
+	opts.ConnMaxIdleTime = cfg.DBPool.ConnMaxIdleTime
+	opts.PingTimeout = cfg.DBPool.ConnectTimeout
+	return opts
+}
+
+// newAuthenticator собирает цепочку аутентификаторов из статических ключей и JWKS
+func newAuthenticator(cfg *config.Config) (interfaces.Authenticator, error) {
+	keys, err := auth.ParseAPIKeys(cfg.AuthAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUTH_API_KEYS: %w", err)
+	}
+	if cfg.AuthAPIKeysFile != "" {
+		fileKeys, err := auth.LoadAPIKeysFile(cfg.AuthAPIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	if cfg.AdminToken != "" {
+		keys = append(keys, auth.APIKey{Name: "admin-token", Key: cfg.AdminToken, Roles: []models.Role{models.RoleAdmin}})
+	}
+
+	var chain auth.Chain
+	if len(keys) > 0 {
+		apiKeys, err := auth.NewAPIKeyAuthenticator(keys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, apiKeys)
+	}
+
+	if cfg.AuthJWKSFile != "" {
+		jwks, err := auth.LoadJWKSFile(cfg.AuthJWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		jwtAuth, err := auth.NewJWTAuthenticator(jwks, auth.JWTOptions{
+			Issuer:     cfg.AuthJWTIssuer,
+			Audience:   cfg.AuthJWTAudience,
+			RolesClaim: cfg.AuthJWTRolesClaim,
+			Leeway:     30 * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwtAuth)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	slog.Info("Authentication enabled", "api_keys", len(keys), "jwks", cfg.AuthJWKSFile != "")
+	return chain, nil
+}
+
+// newPIIPolicy разбирает список ролей, которым персональные данные видны без маскирования
+func newPIIPolicy(spec string) (models.PIIPolicy, error) {
+	var policy models.PIIPolicy
+	for _, value := range strings.Split(spec, ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		role, err := models.ParseRole(value)
+		if err != nil {
+			return models.PIIPolicy{}, err
+		}
+		policy.PrivilegedRoles = append(policy.PrivilegedRoles, role)
+	}
+	return policy, nil
+}
+
+// newRateLimitConfig разбирает ограничения частоты и одновременности HTTP-запросов
+func newRateLimitConfig(cfg *config.Config) (http.RateLimitConfig, error) {
+	perKey, err := http.ParseRateLimit(cfg.RateLimitPerKey)
+	if err != nil {
+		return http.RateLimitConfig{}, fmt.Errorf("RATE_LIMIT_PER_KEY: %w", err)
+	}
+	perIP, err := http.ParseRateLimit(cfg.RateLimitPerIP)
+	if err != nil {
+		return http.RateLimitConfig{}, fmt.Errorf("RATE_LIMIT_PER_IP: %w", err)
+	}
+	routes, err := http.ParseRouteRateLimits(cfg.RateLimitRoutes)
+	if err != nil {
+		return http.RateLimitConfig{}, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+	}
+	proxies, err := http.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return http.RateLimitConfig{}, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
+	return http.RateLimitConfig{
+		PerKey:         perKey,
+		PerIP:          perIP,
+		Routes:         routes,
+		TrustedProxies: proxies,
+		MaxConcurrent:  cfg.MaxConcurrentRequests,
+	}, nil
+}
+
+// newTTLPolicy собирает политику TTL по возрасту заказа
+func newTTLPolicy(cfg *config.Config) models.TTLPolicy {
+	return models.TTLPolicy{
+		Recent:       cfg.CacheTTLRecent,
+		Archive:      cfg.CacheTTLArchive,
+		RecentWindow: cfg.CacheRecentWindow,
+	}
+}
+
+// newFeatures переносит переключатели функций HTTP-сервера из конфигурации
+func newFeatures(cfg *config.Config) http.Features {
+	return http.Features{
+		OrderStream: cfg.FeatureOrderStream,
+		BatchGet:    cfg.FeatureBatchGet,
+	}
+}
+
+// cacheBackend — бэкенд кеша и локальный для реплики уровень, которому нужны инвалидации (nil для чистого Redis)
+type cacheBackend struct {
+	repo  interfaces.CacheRepository
+	local interfaces.CacheRepository
+	// start проверяет доступность Redis; для кеша в памяти ничего не делает
+	start func(ctx context.Context) error
+	close func()
+	// apply применяет TTL и размер кеша при перезагрузке конфигурации
+	apply func(cfg *config.Config) error
+}
+
+// newCacheRepository создает бэкенд кеша, выбранный в конфигурации
+func newCacheRepository(cfg *config.Config) (cacheBackend, error) {
+	if cfg.CacheBackend == "memory" {
+		memoryCache := cache.NewCache(cfg.CacheTTL)
+		memoryCache.SetMaxEntries(cfg.CacheMaxEntries)
+		return cacheBackend{
+			repo:  memoryCache,
+			local: memoryCache,
+			start: func(context.Context) error { return nil },
+			close: func() {},
+			apply: func(cfg *config.Config) error {
+				memoryCache.SetTTL(cfg.CacheTTL)
+				memoryCache.SetMaxEntries(cfg.CacheMaxEntries)
+				return nil
+			},
+		}, nil
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+	closeClient := func() {
+		if err := client.Close(); err != nil {
+			slog.Error("Failed to close Redis client", "error", err)
+		}
+	}
+
+	remote := cache.NewRedisCache(client, cfg.RedisKeyPrefix, cfg.CacheTTL, cfg.RedisTimeout)
+	ping := func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := remote.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to connect to Redis at %s: %w", cfg.RedisAddr, err)
+		}
+		slog.Info("Connected to Redis", "addr", cfg.RedisAddr, "backend", cfg.CacheBackend)
+		return nil
+	}
+
+	if cfg.CacheBackend == "tiered" {
+		local := cache.NewCache(cfg.CacheLocalTTL)
+		local.SetMaxEntries(cfg.CacheMaxEntries)
+		tiered := cache.NewTieredCache(local, remote, cfg.CacheLocalTTL)
+		return cacheBackend{
+			repo:  tiered,
+			local: local,
+			start: ping,
+			close: closeClient,
+			apply: func(cfg *config.Config) error {
+				remote.SetTTL(cfg.CacheTTL)
+				local.SetTTL(cfg.CacheLocalTTL)
+				local.SetMaxEntries(cfg.CacheMaxEntries)
+				tiered.SetLocalTTL(cfg.CacheLocalTTL)
+				return nil
+			},
+		}, nil
+	}
+	return cacheBackend{
+		repo:  remote,
+		start: ping,
+		close: closeClient,
+		apply: func(cfg *config.Config) error {
+			remote.SetTTL(cfg.CacheTTL)
+			return nil
+		},
+	}, nil
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -10,180 +10,191 @@ import (
 	"time"
 
 	"order-service/internal/config"
-	"order-service/internal/infrastructure/cache"
-	"order-service/internal/infrastructure/http"
-	"order-service/internal/infrastructure/kafka"
-	"order-service/internal/infrastructure/postgres"
 	"order-service/internal/logger"
-	"order-service/internal/usecase"
+	"order-service/internal/tracing"
 )
 
 // Остальной код остается тем же
 
 func main() {
+	// Подкоманда "config print" выводит действующую конфигурацию и не запускает сервис
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+
 	// Инициализация логгера
 	logger.InitLogger()
 	slog.Info("Application starting")
 
 	// Загрузка конфигурации
 	cfg, err := config.NewConfig()
-	if err != nil {
+	if config.IsHelp(err) {
+		os.Exit(0)
+	} else if err != nil {
 		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+	// Логгер перенастраивается по конфигурации: формат, вывод и уровни компонентов
+	closeLog, err := setupLogger(cfg)
+	if err != nil {
+		slog.Error("Failed to configure logging", "error", err)
+		os.Exit(1)
+	}
+	defer closeLog()
+
+	// Секреты и строка подключения в лог не выводятся: только адрес БД без учетных данных
 	slog.Info("Configuration loaded",
-		"db_host", cfg.DBHost,
-		"db_port", cfg.DBPort,
+		"profile", cfg.Profile,
+		"db", cfg.DBTarget(),
+		"db_ssl_mode", cfg.DBSSLMode,
 		"kafka_topic", cfg.KafkaTopic,
 		"server_port", cfg.ServerPort)
 
-	// Инициализация репозитория БД
-	db, err := postgres.ConnectToDB(cfg.GetDBConnString())
+	// Изменяемые на лету параметры перечитываются по SIGHUP, при изменении файла и через API
+	reloader := config.NewReloader(cfg, os.Args[1:], os.LookupEnv)
+	reloader.Subscribe("logger", applyLogLevels)
+
+	// Трассировка запускается до остальных компонентов, чтобы их span'ы попали в экспорт
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		FilePath:    cfg.TracingFile,
+		SampleRatio: cfg.TracingSampleRatio,
+		ServiceName: "order-service",
+	})
 	if err != nil {
-		slog.Error("Failed to connect to database", "error", err)
+		slog.Error("Failed to initialize tracing", "error", err)
 		os.Exit(1)
 	}
 	defer func() {
-		if err := db.Close(); err != nil {
-			slog.Error("Failed to close database connection", "error", err)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
 		}
 	}()
-	repo := postgres.NewPostgresRepository(db)
-
-	// Инициализация кеша
-	cacheRepo := cache.NewCache(cfg.CacheTTL)
 
-	// Загрузка заказов в кэш
-	orderUIDs, err := repo.GetAllOrders()
+	// Компоненты запускаются в порядке зависимостей: БД и кеш, потребители, серверы, фоновые задачи
+	app, err := newApplication(cfg, reloader)
 	if err != nil {
-		slog.Error("Failed to get orders for cache initialization", "error", err)
-		slog.Warn("Continuing without initial cache population")
-		orderUIDs = []string{} // Инициализируем пустым слайсом
-	} else {
-		slog.Info("Initializing cache...", "order_count", len(orderUIDs))
-		loadedCount := 0
-
-		// Создаем канал для результатов загрузки
-		type cacheLoadResult struct {
-			orderUID string
-			data     []byte
-			err      error
-		}
-
-		resultChan := make(chan cacheLoadResult, len(orderUIDs))
-
-		// Максимум 5 параллельных запросов
-		sem := make(chan struct{}, 5)
-		for _, orderUID := range orderUIDs {
-			sem <- struct{}{} // блокируемся, если уже 5 горутин работают
-
-			go func(uid string) {
-				defer func() { <-sem }() // освобождаем слот в семафоре
-
-				// Создаем отдельный контекст для каждого запроса
-				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-				defer cancel()
-
-				// Сначала попробуем получить кешированные данные
-				cachedData, err := repo.GetCachedOrderData(uid)
-				if err == nil && len(cachedData) > 0 {
-					// Проверяем, что данные в кеше валидны
-					var order struct{}
-					if err := json.Unmarshal(cachedData, &order); err == nil {
-						resultChan <- cacheLoadResult{uid, cachedData, nil}
-						return
-					}
-				}
-
-				// Если кешированных данных нет, получаем полный заказ
-				order, err := repo.GetOrder(ctx, uid)
-				if err != nil {
-					resultChan <- cacheLoadResult{uid, nil, err}
-					return
-				}
-
-				// Сериализуем заказ для кеша
-				orderData, err := json.Marshal(order)
-				if err != nil {
-					resultChan <- cacheLoadResult{uid, nil, err}
-					return
-				}
-
-				resultChan <- cacheLoadResult{uid, orderData, nil}
-			}(orderUID)
-		}
-
-		// Ждем результаты и добавляем их в кеш
-		for i := 0; i < len(orderUIDs); i++ {
-			result := <-resultChan
-			if result.err != nil {
-				slog.Error("Failed to load order for cache", "error", result.err, "orderUID", result.orderUID)
-				continue
-			}
+		slog.Error("Failed to initialize application", "error", err)
+		os.Exit(1)
+	}
 
-			// Добавляем в кеш
-			cacheRepo.Set(result.orderUID, result.data)
+	// Обрабатываем сигналы для корректного завершения
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-			// Также сохраняем в БД для быстрого восстановления кеша
-			if err := repo.CacheOrderData(result.orderUID, result.data); err != nil {
-				slog.Error("Failed to save order cache to DB", "error", err, "orderUID", result.orderUID)
-			}
+	// Сигнал во время запуска прерывает его: уже запущенные компоненты останавливаются
+	startCtx, stopStart := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err = app.Start(startCtx)
+	stopStart()
+	if err != nil {
+		slog.Error("Failed to start application", "error", err)
+		os.Exit(1)
+	}
 
-			loadedCount++
-		}
+	// Ожидаем сигнала завершения или отказа компонента, который не удалось перезапустить
+	exitCode := 0
+	select {
+	case sig := <-sigChan:
+		slog.Info("Received shutdown signal", "signal", sig)
+	case err := <-app.Failed():
+		slog.Error("Component failed, shutting down", "error", err)
+		exitCode = 1
+	}
 
-		// Ждем, пока все горутины завершатся
-		for i := 0; i < cap(sem); i++ {
-			sem <- struct{}{}
-		}
+	// Корректно завершаем работу приложения: компоненты останавливаются в обратном порядке
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer shutdownCancel()
 
-		slog.Info("Cache initialized successfully", "loaded", loadedCount, "total", len(orderUIDs))
-		// Инициализация приложения с нужными компонентами
-		app := usecase.NewApplication(
-			cfg,
-			repo,
-			cacheRepo,
-			kafka.NewOrderKafkaConsumer(
-				cfg.KafkaBrokers,
-				cfg.KafkaTopic,
-				cfg.KafkaGroupID,
-				repo,
-				cacheRepo,
-			),
-			http.NewOrderHTTPServer(
-				cfg.ServerPort,
-				repo,
-				cacheRepo,
-			),
-		)
-
-		// Запуск приложения
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shutdown application gracefully", "error", err)
+		os.Exit(1)
+	}
 
-		// Запускаем все компоненты
-		if err := app.Start(ctx); err != nil {
-			slog.Error("Failed to start application", "error", err)
-			os.Exit(1)
-		}
+	slog.Info("Application shutdown completed")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
 
-		// Обрабатываем сигналы для корректного завершения
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+// runConfigCommand выполняет подкоманду config и возвращает код завершения
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: order_service config print [flags]")
+		return 2
+	}
 
-		// Ожидаем сигнала завершения
-		sig := <-sigChan
-		slog.Info("Received shutdown signal", "signal", sig)
+	cfg, err := config.Load(args[1:], os.LookupEnv)
+	if config.IsHelp(err) {
+		return 0
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
 
-		// Корректно завершаем работу приложения
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer shutdownCancel()
+// setupLogger настраивает логгер по конфигурации и возвращает функцию закрытия файла логов
+func setupLogger(cfg *config.Config) (func(), error) {
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return nil, err
+	}
+	components, err := logger.ParseComponentLevels(cfg.Log.Components)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := app.Shutdown(shutdownCtx); err != nil {
-			slog.Error("Failed to shutdown application gracefully", "error", err)
-			os.Exit(1)
+	opts := logger.Options{
+		Level:      level,
+		Format:     cfg.Log.Format,
+		AddSource:  cfg.Log.AddSource,
+		Output:     os.Stdout,
+		Components: components,
+	}
+	if cfg.Log.Redact {
+		opts.Redact = &logger.RedactOptions{Allow: cfg.Log.RedactAllow, Deny: cfg.Log.RedactDeny}
+	}
+	closeLog := func() {}
+	switch cfg.Log.Output {
+	case "stderr":
+		opts.Output = os.Stderr
+	case "file":
+		file, err := logger.OpenRotatingFile(cfg.Log.File, cfg.Log.MaxSizeMB, cfg.Log.MaxBackups)
+		if err != nil {
+			return nil, err
 		}
+		opts.Output = file
+		closeLog = func() {
+			if err := file.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, "failed to close log file:", err)
+			}
+		}
+	}
 
-		slog.Info("Application shutdown completed")
+	if err := logger.Setup(opts); err != nil {
+		closeLog()
+		return nil, err
+	}
+	return closeLog, nil
+}
+
+// applyLogLevels устанавливает общий уровень логирования и уровни компонентов
+func applyLogLevels(cfg *config.Config) error {
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return err
+	}
+	components, err := logger.ParseComponentLevels(cfg.Log.Components)
+	if err != nil {
+		return err
 	}
+	logger.SetLevel(level)
+	logger.SetComponentLevels(components)
+	return nil
 }
@@ -9,7 +9,8 @@ CREATE TABLE orders (
     delivery_service VARCHAR(50),
     shardkey VARCHAR(10),
     sm_id INT,
-    date_created TIMESTAMP,
+    -- Заказы без даты отклоняются при приеме: по ней строятся список заказов и прогрев кеша
+    date_created TIMESTAMP NOT NULL,
     oof_shard VARCHAR(10)
 );
 
@@ -2,106 +2,272 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// Config — действующая конфигурация сервиса; параметры и их источники описаны в settings.go
 type Config struct {
-	// PostgreSQL
-	DBHost      string
-	DBPort      int
-	DBUser      string
-	DBPassword  string
-	DBName      string
-	DBSSLMode   string
-	DBMaxConns  int
-	DBIdleConns int
+	// File — путь к файлу конфигурации, если он был задан
+	File string
+	// Profile — профиль значений по умолчанию: prod или dev
+	Profile string
+	// Источник каждого параметра для config print
+	sources map[string]string
+
+	// PostgreSQL; DBURL (postgres://...) заменяет отдельные параметры подключения
+	DBURL      string
+	DBHost     string
+	DBPort     int
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
+	// Файлы корневого сертификата, сертификата и ключа клиента для TLS
+	DBSSLRootCert string
+	DBSSLCert     string
+	DBSSLKey      string
+	DBPool        DBPoolConfig
 
 	// Kafka
 	KafkaBrokers []string
 	KafkaTopic   string
 	KafkaGroupID string
+	Consumer     ConsumerConfig
+
+	// Инвалидация кеша между репликами
+	InstanceID                   string
+	CacheInvalidationEnabled     bool
+	CacheInvalidationTopic       string
+	CacheInvalidationGroupPrefix string
 
 	// HTTP Server
-	ServerPort int
+	ServerPort   int
+	HTTPTimeouts HTTPTimeoutsConfig
+	// AdminToken — устаревший ключ администратора, эквивалентен ключу с ролью admin
+	AdminToken string
+	// max-age в Cache-Control для заказов; 0 — клиент должен перепроверять ответ через ETag
+	HTTPCacheMaxAge time.Duration
+
+	// Аутентификация: статические ключи (name:role1|role2:key через запятую) и JWT с локальным JWKS
+	AuthAPIKeys       string
+	AuthAPIKeysFile   string
+	AuthJWKSFile      string
+	AuthJWTIssuer     string
+	AuthJWTAudience   string
+	AuthJWTRolesClaim string
+	// Роли маршрутов заказов: "GET /orders/{uid}=support|admin;..."
+	AuthRouteRoles string
+	// Роли через запятую, которым персональные данные отдаются без маскирования
+	PIIPrivilegedRoles string
+
+	// Ограничение частоты запросов: "rate:burst" (запросов в секунду и емкость), "0:1" отключает
+	RateLimitPerKey string
+	RateLimitPerIP  string
+	// Ограничения отдельных маршрутов: "GET /order=5:10;..."
+	RateLimitRoutes string
+	// Доверенные прокси для X-Forwarded-For: сети и адреса через запятую
+	TrustedProxies string
+	// Предел одновременно обрабатываемых запросов к заказам; 0 — без ограничения
+	MaxConcurrentRequests int
+
+	// gRPC Server; 0 отключает сервер
+	GRPCPort int
+
+	// Количество последних событий о заказах, доступных для возобновления подписки
+	OrderEventsHistory int
 
 	// Cache
-	CacheTTL time.Duration
+	CacheTTL      time.Duration
+	CacheBackend  string // memory, redis или tiered
+	CacheLocalTTL time.Duration
+	// Предел числа элементов локального кеша; 0 — без ограничения
+	CacheMaxEntries int
+
+	// Per-entry TTL и refresh-ahead
+	CacheTTLRecent          time.Duration
+	CacheTTLArchive         time.Duration
+	CacheRecentWindow       time.Duration
+	CacheRefreshAheadWindow time.Duration // 0 — refresh-ahead выключен
+
+	// Redis
+	RedisAddr      string
+	RedisPassword  string
+	RedisDB        int
+	RedisKeyPrefix string
+	RedisTimeout   time.Duration
+
+	// Cache warm-up
+	WarmupBatchSize   int
+	WarmupMaxAge      time.Duration
+	WarmupMaxOrders   int
+	WarmupConcurrency int
+
+	// Трассировка: none, otlp (адрес в OTEL_EXPORTER_OTLP_ENDPOINT), stdout или file
+	TracingExporter    string
+	TracingFile        string
+	TracingSampleRatio float64
+
+	// Время на корректное завершение всех компонентов и каждого в отдельности
+	ShutdownTimeout          time.Duration
+	ShutdownComponentTimeout time.Duration
+
+	// Перезапуск потребителей Kafka и фоновых задач после отказа во время работы
+	Restart RestartConfig
+
+	// Логирование
+	Log LogConfig
+
+	// Функции, которые можно выключить без перезапуска
+	FeatureOrderStream bool
+	FeatureBatchGet    bool
+
+	// Период проверки файла конфигурации на изменения; 0 — только по SIGHUP и через API
+	ReloadWatchInterval time.Duration
 }
 
-func NewConfig() (*Config, error) {
-	config := &Config{
-		// PostgreSQL defaults
-		DBHost:      getEnv("DB_HOST", "localhost"),
-		DBUser:      getEnv("DB_USER", "my_user"),
-		DBPassword:  getEnv("DB_PASSWORD", "1"),
-		DBName:      getEnv("DB_NAME", "my_database"),
-		DBSSLMode:   getEnv("DB_SSL_MODE", "disable"),
-		DBMaxConns:  getEnvAsInt("DB_MAX_CONNS", 25),
-		DBIdleConns: getEnvAsInt("DB_IDLE_CONNS", 5),
-
-		// Kafka defaults
-		KafkaTopic:   getEnv("KAFKA_TOPIC", "orders"),
-		KafkaGroupID: getEnv("KAFKA_GROUP_ID", "order-consumer-group"),
-
-		// HTTP Server defaults
-		ServerPort: getEnvAsInt("SERVER_PORT", 8081),
-
-		// Cache defaults
-		CacheTTL: getEnvAsDuration("CACHE_TTL", 30*time.Minute),
-	}
+// RestartConfig — перезапуск отказавших компонентов; после MaxAttempts неудачных попыток сервис завершается
+type RestartConfig struct {
+	MaxAttempts int
+	// Backoff — пауза перед первым перезапуском, каждая следующая вдвое дольше, но не больше MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
 
-	// Get DB port
-	var err error
-	config.DBPort, err = strconv.Atoi(getEnv("DB_PORT", "5434"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %v", err)
-	}
+// LogConfig — уровень, формат и назначение логов
+type LogConfig struct {
+	// Level — debug, info, warn или error; Components переопределяет его: "kafka=debug,postgres=warn"
+	Level      string
+	Components string
+	// Format — json, text или pretty
+	Format    string
+	AddSource bool
+	// Output — stdout, stderr или file; для file запись идет в File с ротацией по размеру
+	Output     string
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	// Redact маскирует персональные данные и секреты; RedactDeny добавляет поля к стандартному
+	// списку, RedactAllow исключает поля из маскирования
+	Redact      bool
+	RedactAllow []string
+	RedactDeny  []string
+}
 
-	// Kafka brokers
-	brokersStr := getEnv("KAFKA_BROKERS", "localhost:9092")
-	config.KafkaBrokers = strings.Split(brokersStr, ",")
+// DBPoolConfig — пул соединений и таймауты PostgreSQL
+type DBPoolConfig struct {
+	MaxConns        int
+	IdleConns       int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout ограничивает запросы на стороне сервера; 0 — без ограничения
+	StatementTimeout time.Duration
+	// ConnectTimeout — таймаут одной попытки подключения
+	ConnectTimeout time.Duration
+	// ConnectMaxWait — сколько ждать готовности PostgreSQL при запуске, повторяя попытки
+	ConnectMaxWait time.Duration
+}
 
-	return config, nil
+// HTTPTimeoutsConfig — таймауты HTTP-сервера
+type HTTPTimeoutsConfig struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
 }
 
-func (c *Config) GetDBConnString() string {
-	return fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName, c.DBSSLMode,
-	)
+// ConsumerConfig — таймауты обработки сообщений Kafka
+type ConsumerConfig struct {
+	FetchTimeout time.Duration
+	SaveTimeout  time.Duration
 }
 
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// GetDBConnString возвращает строку подключения для lib/pq. Содержит пароль — не логировать,
+// для логов есть DBTarget.
+func (c *Config) GetDBConnString() string {
+	params := c.dbParams()
+	if c.DBURL != "" {
+		if u, err := url.Parse(c.DBURL); err == nil {
+			// Параметры из URL важнее настроек по отдельности
+			query := u.Query()
+			for _, p := range params {
+				if !query.Has(p.key) {
+					query.Set(p.key, p.value)
+				}
+			}
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+
+	params = append([]dbParam{
+		{"host", c.DBHost},
+		{"port", strconv.Itoa(c.DBPort)},
+		{"user", c.DBUser},
+		{"password", c.DBPassword},
+		{"dbname", c.DBName},
+	}, params...)
+
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, p.key+"="+quoteConnValue(p.value))
 	}
-	return value
+	return strings.Join(parts, " ")
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := getEnv(key, "")
-	if valueStr == "" {
-		return defaultValue
+type dbParam struct {
+	key, value string
+}
+
+// dbParams возвращает параметры подключения, общие для URL и строки key=value
+func (c *Config) dbParams() []dbParam {
+	params := []dbParam{{"sslmode", c.DBSSLMode}}
+	for _, p := range []dbParam{{"sslrootcert", c.DBSSLRootCert}, {"sslcert", c.DBSSLCert}, {"sslkey", c.DBSSLKey}} {
+		if p.value != "" {
+			params = append(params, p)
+		}
+	}
+	// lib/pq принимает таймаут подключения в секундах, а неизвестные параметры передает серверу
+	if seconds := int(c.DBPool.ConnectTimeout.Seconds()); seconds > 0 {
+		params = append(params, dbParam{"connect_timeout", strconv.Itoa(seconds)})
+	}
+	if c.DBPool.StatementTimeout > 0 {
+		params = append(params, dbParam{"statement_timeout", strconv.FormatInt(c.DBPool.StatementTimeout.Milliseconds(), 10)})
 	}
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
-		return defaultValue
+	return params
+}
+
+// quoteConnValue экранирует значение для строки key=value: пустые значения и значения
+// с пробелами или кавычками заключаются в одинарные кавычки
+func quoteConnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
 	}
-	return value
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
 
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	valueStr := getEnv(key, "")
-	if valueStr == "" {
-		return defaultValue
+// DBTarget описывает базу данных для логов, без учетных данных
+func (c *Config) DBTarget() string {
+	if c.DBURL != "" {
+		if u, err := url.Parse(c.DBURL); err == nil {
+			return u.Host + u.Path
+		}
+		return "[invalid DATABASE_URL]"
 	}
-	value, err := time.ParseDuration(valueStr)
-	if err != nil {
-		return defaultValue
+	return fmt.Sprintf("%s:%d/%s", c.DBHost, c.DBPort, c.DBName)
+}
+
+// defaultInstanceID возвращает идентификатор реплики: имя хоста и случайный суффикс,
+// чтобы перезапущенная реплика не унаследовала смещения группы предыдущей
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "order-service"
 	}
-	return value
+	return hostname + "-" + uuid.NewString()[:8]
 }
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Источники значений в порядке возрастания приоритета
+const (
+	sourceDefault = "default"
+	sourceProfile = "profile"
+	sourceFile    = "file"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+)
+
+// configFileEnv задает путь к файлу конфигурации, если не указан флаг -config
+const configFileEnv = "CONFIG_FILE"
+
+// profileEnv задает профиль, если не указан флаг -profile
+const profileEnv = "APP_PROFILE"
+
+// Профили значений по умолчанию
+const (
+	profileProd = "prod"
+	profileDev  = "dev"
+)
+
+// ValidationError перечисляет все ошибки конфигурации сразу, а не только первую
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// NewConfig загружает конфигурацию из флагов командной строки, окружения и файла
+func NewConfig() (*Config, error) {
+	return Load(os.Args[1:], os.LookupEnv)
+}
+
+// Load собирает конфигурацию с приоритетом: флаги > переменные окружения > файл > значения по умолчанию.
+// Файл задается флагом -config или переменной CONFIG_FILE; поддерживаются YAML и TOML.
+// Ошибки разбора и проверки собираются вместе и возвращаются одной ValidationError.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := &Config{sources: make(map[string]string)}
+	settings := config.settings()
+
+	var problems []string
+	apply := func(s setting, raw, source string) {
+		if err := s.set(raw); err != nil {
+			shown := strconv.Quote(raw)
+			if s.secret {
+				shown = redacted
+			}
+			problems = append(problems, fmt.Sprintf("%s: invalid value %s from %s: %v", s.key, shown, source, err))
+			return
+		}
+		config.sources[s.key] = source
+	}
+
+	// Флаги разбираются первыми, чтобы узнать путь к файлу, но применяются последними
+	flagValues, err := parseFlags(args, settings, &config.File, &config.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if config.File == "" {
+		config.File, _ = lookupEnv(configFileEnv)
+	}
+	if config.Profile == "" {
+		config.Profile, _ = lookupEnv(profileEnv)
+	}
+	if config.Profile == "" {
+		config.Profile = profileProd
+	}
+	if config.Profile != profileProd && config.Profile != profileDev {
+		problems = append(problems, fmt.Sprintf("profile: %q is not one of [%s %s]", config.Profile, profileProd, profileDev))
+	}
+
+	for _, s := range settings {
+		value, source := s.defaultFor(config.Profile)
+		apply(s, value, source)
+	}
+
+	if config.File != "" {
+		values, err := readFile(config.File)
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[string]setting, len(settings))
+		for _, s := range settings {
+			byKey[s.key] = s
+		}
+		for _, key := range sortedKeys(values) {
+			s, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown key in %s", key, config.File))
+				continue
+			}
+			apply(s, values[key], sourceFile)
+		}
+	}
+
+	for _, s := range settings {
+		value, _ := lookupEnv(s.env)
+		fileEnv := s.fileEnv(settings)
+		path := ""
+		if fileEnv != "" {
+			path, _ = lookupEnv(fileEnv)
+		}
+
+		switch {
+		case value != "" && path != "":
+			problems = append(problems, fmt.Sprintf("%s: both %s and %s are set", s.key, s.env, fileEnv))
+		case path != "":
+			secret, err := readSecretFile(path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: %v", s.key, fileEnv, err))
+				continue
+			}
+			apply(s, secret, sourceEnv+" "+fileEnv)
+		case value != "":
+			apply(s, value, sourceEnv+" "+s.env)
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := flagValues[s.key]; ok {
+			apply(s, value, sourceFlag+" -"+s.flagName())
+		}
+	}
+
+	if config.InstanceID == "" {
+		config.InstanceID = defaultInstanceID()
+	}
+
+	problems = append(problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return config, nil
+}
+
+// readSecretFile читает секрет из файла; завершающий перевод строки отбрасывается
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// parseFlags регистрирует флаг на каждый параметр и возвращает явно заданные значения по ключам
+func parseFlags(args []string, settings []setting, file, profile *string) (map[string]string, error) {
+	fs := flag.NewFlagSet("order-service", flag.ContinueOnError)
+	fs.StringVar(file, "config", "", "файл конфигурации YAML или TOML (также CONFIG_FILE)")
+	fs.StringVar(profile, "profile", "", "профиль значений по умолчанию: prod или dev (также APP_PROFILE)")
+
+	values := make(map[string]string)
+	for _, s := range settings {
+		key := s.key
+		usage := fmt.Sprintf("%s (%s)", s.usage, s.env)
+		if s.isBool() {
+			fs.BoolFunc(s.flagName(), usage, func(value string) error {
+				values[key] = value
+				return nil
+			})
+			continue
+		}
+		fs.Func(s.flagName(), usage, func(value string) error {
+			values[key] = value
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return values, nil
+}
+
+// readFile читает файл конфигурации и раскладывает вложенные секции в ключи вида db.host
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file %q: expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, values map[string]string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(key, value, values)
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsHelp сообщает, что пользователь запросил справку по флагам
+func IsHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
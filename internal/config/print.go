@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const redacted = "[REDACTED]"
+
+// Print выводит действующую конфигурацию в формате YAML с источником каждого значения.
+// Секреты заменяются на [REDACTED]; вывод можно использовать как файл конфигурации.
+func (c *Config) Print(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "# Effective configuration (precedence: flags > env > file > defaults)"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "# Profile: %s\n", c.Profile); err != nil {
+		return err
+	}
+	if c.File != "" {
+		if _, err := fmt.Fprintf(w, "# Config file: %s\n", c.File); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range c.settings() {
+		value := s.format()
+		if s.secret && value != `""` {
+			value = strconv.Quote(redacted)
+		}
+
+		source := c.sources[s.key]
+		if source == "" {
+			source = sourceDefault
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s # %s\n", s.key, value, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"order-service/internal/domain/models"
+)
+
+// reloadableKeys — параметры, которые применяются без перезапуска; остальные изменения
+// перезагрузка только сообщает как требующие перезапуска
+var reloadableKeys = map[string]bool{
+	"log.level":                    true,
+	"log.components":               true,
+	"cache.ttl":                    true,
+	"cache.local_ttl":              true,
+	"cache.max_entries":            true,
+	"cache.ttl_recent":             true,
+	"cache.ttl_archive":            true,
+	"cache.recent_window":          true,
+	"rate_limit.per_key":           true,
+	"rate_limit.per_ip":            true,
+	"rate_limit.routes":            true,
+	"http.trusted_proxies":         true,
+	"http.max_concurrent_requests": true,
+	"features.order_stream":        true,
+	"features.batch_get":           true,
+}
+
+// subscriber — компонент, применяющий изменившуюся конфигурацию
+type subscriber struct {
+	name  string
+	apply func(cfg *Config) error
+}
+
+// Reloader перечитывает конфигурацию из тех же флагов, окружения и файла, что и при запуске,
+// и передает подписчикам новую конфигурацию, если изменились параметры из reloadableKeys
+type Reloader struct {
+	args      []string
+	lookupEnv func(string) (string, bool)
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []subscriber
+	last        *models.ConfigReload
+}
+
+// NewReloader создает перезагрузчик для конфигурации, загруженной из args и lookupEnv
+func NewReloader(current *Config, args []string, lookupEnv func(string) (string, bool)) *Reloader {
+	return &Reloader{
+		args:      args,
+		lookupEnv: lookupEnv,
+		current:   current,
+	}
+}
+
+// Subscribe регистрирует компонент; apply вызывается при каждой перезагрузке с изменениями
+func (r *Reloader) Subscribe(name string, apply func(cfg *Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers = append(r.subscribers, subscriber{name: name, apply: apply})
+}
+
+// Current возвращает действующую конфигурацию
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current
+}
+
+// LastReload возвращает результат последней перезагрузки
+func (r *Reloader) LastReload() (models.ConfigReload, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		return models.ConfigReload{}, false
+	}
+	return *r.last, true
+}
+
+// Reload перечитывает конфигурацию. Некорректная конфигурация не применяется целиком;
+// из корректной применяются только изменяемые на лету параметры.
+func (r *Reloader) Reload(trigger string) models.ConfigReload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := models.ConfigReload{Trigger: trigger, At: time.Now().UTC()}
+	defer func() {
+		r.last = &result
+		logReload(result)
+	}()
+
+	loaded, err := Load(r.args, r.lookupEnv)
+	if err != nil {
+		result.Status = models.ReloadStatusFailed
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			result.Errors = validationErr.Problems
+		} else {
+			result.Errors = []string{err.Error()}
+		}
+		return result
+	}
+
+	// Новая конфигурация — копия действующей с обновленными изменяемыми параметрами
+	next := r.current.clone()
+	currentSettings, loadedSettings, nextSettings := r.current.settings(), loaded.settings(), next.settings()
+	for i, s := range currentSettings {
+		if r.current.sources[s.key] == sourceDefault && loaded.sources[s.key] == sourceDefault {
+			// Значения по умолчанию могут вычисляться при каждой загрузке, как instance_id
+			continue
+		}
+		if reflect.DeepEqual(reflect.ValueOf(s.field).Elem().Interface(), reflect.ValueOf(loadedSettings[i].field).Elem().Interface()) {
+			continue
+		}
+		if !reloadableKeys[s.key] {
+			result.RestartRequired = append(result.RestartRequired, s.key)
+			continue
+		}
+		reflect.ValueOf(nextSettings[i].field).Elem().Set(reflect.ValueOf(loadedSettings[i].field).Elem())
+		next.sources[s.key] = loaded.sources[s.key]
+		result.Changed = append(result.Changed, s.key)
+	}
+
+	if len(result.Changed) == 0 {
+		result.Status = models.ReloadStatusUnchanged
+		return result
+	}
+
+	result.Status = models.ReloadStatusApplied
+	for _, sub := range r.subscribers {
+		if err := sub.apply(next); err != nil {
+			result.Status = models.ReloadStatusPartial
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", sub.name, err))
+		}
+	}
+	r.current = next
+	return result
+}
+
+func logReload(result models.ConfigReload) {
+	attrs := []any{"trigger", result.Trigger, "status", result.Status, "changed", result.Changed}
+	if len(result.RestartRequired) > 0 {
+		attrs = append(attrs, "restart_required", result.RestartRequired)
+	}
+	switch result.Status {
+	case models.ReloadStatusFailed, models.ReloadStatusPartial:
+		slog.Error("Configuration reload failed", append(attrs, "errors", result.Errors)...)
+	default:
+		slog.Info("Configuration reloaded", attrs...)
+	}
+}
+
+// Watch перезагружает конфигурацию по SIGHUP и при изменении файла конфигурации,
+// проверяя его раз в interval (0 — файл не отслеживается). Завершается с отменой ctx.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	file := r.Current().File
+	if interval > 0 && file != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	modTime := fileModTime(file)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			r.Reload(models.ReloadTriggerSignal)
+		case <-tick:
+			if changed := fileModTime(file); !changed.Equal(modTime) {
+				modTime = changed
+				r.Reload(models.ReloadTriggerFile)
+			}
+		}
+	}
+}
+
+func fileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// clone возвращает копию конфигурации с собственной картой источников
+func (c *Config) clone() *Config {
+	copied := *c
+	copied.sources = make(map[string]string, len(c.sources))
+	for key, source := range c.sources {
+		copied.sources[key] = source
+	}
+	return &copied
+}
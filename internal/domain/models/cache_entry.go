@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CacheEntryInfo описывает отдельный элемент кеша
+type CacheEntryInfo struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+	// CreatedAt — время записи; нулевое, если бэкенд его не хранит
+	CreatedAt time.Time     `json:"created_at,omitempty"`
+	Age       time.Duration `json:"age"`
+	// TTL — оставшееся время жизни; отрицательное, если элемент бессрочный
+	TTL time.Duration `json:"ttl"`
+}
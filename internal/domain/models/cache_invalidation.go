@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CacheInvalidation — сообщение об инвалидации кеша, рассылаемое между репликами
+type CacheInvalidation struct {
+	Origin string    `json:"origin"` // идентификатор реплики-отправителя
+	Keys   []string  `json:"keys,omitempty"`
+	Prefix string    `json:"prefix,omitempty"`
+	Flush  bool      `json:"flush,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
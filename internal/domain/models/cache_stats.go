@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CacheStats — сводная статистика бэкенда кеша
+type CacheStats struct {
+	Backend  string  `json:"backend"`
+	Entries  int64   `json:"entries"`
+	Bytes    int64   `json:"bytes"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+	// OldestEntry — время записи самого старого элемента; нулевое, если бэкенд его не знает
+	OldestEntry time.Time `json:"oldest_entry,omitempty"`
+	// Layers заполняется для многоуровневого кеша: статистика каждого уровня
+	Layers []CacheStats `json:"layers,omitempty"`
+}
+
+// ComputeHitRatio вычисляет долю попаданий по счетчикам Hits и Misses
+func (s *CacheStats) ComputeHitRatio() {
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRatio = float64(s.Hits) / float64(total)
+	}
+}
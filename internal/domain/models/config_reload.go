@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Источники перезагрузки конфигурации
+const (
+	ReloadTriggerSignal = "signal"
+	ReloadTriggerFile   = "file"
+	ReloadTriggerAPI    = "api"
+)
+
+// Итог перезагрузки конфигурации
+const (
+	ReloadStatusApplied   = "applied"   // изменения применены всеми подписчиками
+	ReloadStatusPartial   = "partial"   // часть подписчиков вернула ошибку
+	ReloadStatusUnchanged = "unchanged" // изменяемые на лету параметры не изменились
+	ReloadStatusFailed    = "failed"    // конфигурация не прошла проверку и не применена
+)
+
+// ConfigReload — результат перезагрузки конфигурации
+type ConfigReload struct {
+	Trigger string    `json:"trigger"`
+	Status  string    `json:"status"`
+	At      time.Time `json:"at"`
+	// Changed — примененные параметры, RestartRequired — измененные параметры, которые вступят в силу после перезапуска
+	Changed         []string `json:"changed,omitempty"`
+	RestartRequired []string `json:"restart_required,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+}
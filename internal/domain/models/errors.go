@@ -0,0 +1,27 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+)
+
+var (
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrInvalidOrderUID = errors.New("invalid order uid")
+	ErrBatchTooLarge   = errors.New("too many order uids in batch")
+	// ErrInvalidOrder — заказ не прошел проверку и не может быть сохранен; повторная обработка не поможет
+	ErrInvalidOrder = errors.New("invalid order")
+	// ErrWarmupInProgress возвращается, если прогрев кеша уже выполняется
+	ErrWarmupInProgress = errors.New("cache warm-up already in progress")
+)
+
+// MaxBatchOrders — максимальное число заказов в одном пакетном запросе
+const MaxBatchOrders = 500
+
+// Допустимый идентификатор заказа: латиница, цифры, '-' и '_', не длиннее колонки order_uid
+var orderUIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,255}$`)
+
+// ValidOrderUID проверяет формат идентификатора заказа
+func ValidOrderUID(orderUID string) bool {
+	return orderUIDPattern.MatchString(orderUID)
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Order struct {
 	OrderUID          string    `json:"order_uid"`
@@ -55,3 +58,31 @@ type Item struct {
 	Brand       string  `json:"brand"`
 	Status      int     `json:"status"`
 }
+
+// Validate проверяет, что заказ можно сохранить; ошибка оборачивает ErrInvalidOrder.
+// date_created обязателен: по нему постранично выбираются заказы для списка и прогрева кеша.
+func (o Order) Validate() error {
+	switch {
+	case !ValidOrderUID(o.OrderUID):
+		return fmt.Errorf("%w: %w", ErrInvalidOrder, ErrInvalidOrderUID)
+	case o.TrackNumber == "":
+		return fmt.Errorf("%w: track_number is required", ErrInvalidOrder)
+	case len(o.Items) == 0:
+		return fmt.Errorf("%w: at least one item is required", ErrInvalidOrder)
+	case o.DateCreated.IsZero():
+		return fmt.Errorf("%w: date_created is required", ErrInvalidOrder)
+	}
+	return nil
+}
+
+// IngestResult — чем закончилось сохранение входящего заказа
+type IngestResult string
+
+const (
+	// OrderIngestCreated — новый заказ сохранен
+	OrderIngestCreated IngestResult = "created"
+	// OrderIngestUpdated — содержимое существующего заказа изменилось (корректировка)
+	OrderIngestUpdated IngestResult = "updated"
+	// OrderIngestDuplicate — заказ совпадает с сохраненным, ничего не изменилось
+	OrderIngestDuplicate IngestResult = "duplicate"
+)
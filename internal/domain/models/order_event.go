@@ -0,0 +1,47 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type OrderEventType string
+
+const (
+	OrderCreated OrderEventType = "created"
+	OrderUpdated OrderEventType = "updated"
+)
+
+// OrderEvent — событие о сохранении заказа; ID назначается шиной и монотонно растет
+type OrderEvent struct {
+	ID    uint64         `json:"id"`
+	Type  OrderEventType `json:"type"`
+	Order Order          `json:"order"`
+	At    time.Time      `json:"at"`
+}
+
+// OrderEventFilter отбирает события по параметрам заказа; пустой список не ограничивает выборку
+type OrderEventFilter struct {
+	DeliveryServices []string
+	Regions          []string
+	Currencies       []string
+}
+
+// Match проверяет заказ по всем условиям фильтра без учета регистра
+func (f OrderEventFilter) Match(order Order) bool {
+	return matchAny(f.DeliveryServices, order.DeliveryService) &&
+		matchAny(f.Regions, order.Delivery.Region) &&
+		matchAny(f.Currencies, order.Payment.Currency)
+}
+
+func matchAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// OrderSnapshot — сериализованное состояние заказа, используемое для прогрева кеша
+type OrderSnapshot struct {
+	OrderUID    string
+	DateCreated time.Time
+	Data        []byte // nil, если для заказа нет записи в order_cache
+}
+
+// OrderPageQuery описывает постраничную выборку заказов от новых к старым
+type OrderPageQuery struct {
+	Limit int
+	// Since ограничивает выборку заказами, созданными не раньше указанного момента
+	Since time.Time
+	// AfterCreated и AfterUID — курсор: ключ последнего заказа предыдущей страницы
+	AfterCreated time.Time
+	AfterUID     string
+}
+
+// OrderPageCursor — ключ последнего заказа страницы, с которого продолжается выборка
+type OrderPageCursor struct {
+	Created time.Time
+	UID     string
+}
+
+// OrderPage — страница заказов и курсор следующей страницы
+type OrderPage struct {
+	Orders []Order
+	// Next строится по последней строке выборки из БД, а не по Orders: заказы, пропущенные
+	// при загрузке, не обрывают листание. nil, если выборка была неполной и страниц больше нет.
+	Next *OrderPageCursor
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// piiRule описывает поле заказа с персональными данными и способ его маскирования
+type piiRule struct {
+	field string
+	value func(order *Order) *string
+	mask  func(value string) string
+}
+
+// Все поля с персональными данными перечислены здесь; транспорты применяют их через PIIPolicy.
+// Город и регион не маскируются: по ним фильтруется поток событий.
+var orderPIIRules = []piiRule{
+	{"delivery.name", func(o *Order) *string { return &o.Delivery.Name }, MaskWords},
+	{"delivery.phone", func(o *Order) *string { return &o.Delivery.Phone }, MaskPhone},
+	{"delivery.zip", func(o *Order) *string { return &o.Delivery.Zip }, MaskZip},
+	{"delivery.address", func(o *Order) *string { return &o.Delivery.Address }, MaskWords},
+	{"delivery.email", func(o *Order) *string { return &o.Delivery.Email }, MaskEmail},
+}
+
+// PIIPolicy определяет, кто видит персональные данные без маскирования
+type PIIPolicy struct {
+	PrivilegedRoles []Role
+}
+
+// DefaultPIIPolicy открывает персональные данные только администраторам
+func DefaultPIIPolicy() PIIPolicy {
+	return PIIPolicy{PrivilegedRoles: []Role{RoleAdmin}}
+}
+
+// Reveals сообщает, видит ли вызывающая сторона персональные данные; анонимные запросы их не видят
+func (p PIIPolicy) Reveals(principal *Principal) bool {
+	return principal.HasAnyRole(p.PrivilegedRoles...)
+}
+
+// Apply возвращает копию заказа, замаскированную для вызывающей стороны
+func (p PIIPolicy) Apply(principal *Principal, order Order) Order {
+	if p.Reveals(principal) {
+		return order
+	}
+	for _, rule := range orderPIIRules {
+		if value := rule.value(&order); *value != "" {
+			*value = rule.mask(*value)
+		}
+	}
+	return order
+}
+
+// MaskPhone оставляет код страны и четыре последние цифры: +7*****1234
+func MaskPhone(phone string) string {
+	var digits []rune
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) <= 5 {
+		return strings.Repeat("*", len(digits))
+	}
+
+	var b strings.Builder
+	if strings.HasPrefix(strings.TrimSpace(phone), "+") {
+		b.WriteByte('+')
+	}
+	b.WriteRune(digits[0])
+	b.WriteString(strings.Repeat("*", len(digits)-5))
+	b.WriteString(string(digits[len(digits)-4:]))
+	return b.String()
+}
+
+// MaskEmail оставляет первый символ имени и домен: u***@example.com
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return MaskWords(email)
+	}
+	return firstRune(local) + "***@" + domain
+}
+
+// MaskZip оставляет две первые цифры индекса
+func MaskZip(zip string) string {
+	runes := []rune(zip)
+	if len(runes) <= 2 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-2)
+}
+
+// MaskWords оставляет первую букву каждого слова: Test Testov → T*** T***
+func MaskWords(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		words[i] = firstRune(word) + "***"
+	}
+	return strings.Join(words, " ")
+}
+
+func firstRune(value string) string {
+	for _, r := range value {
+		return string(r)
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials — запрос не содержит учетных данных, подходящих аутентификатору
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials — учетные данные есть, но не прошли проверку
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Role — роль вызывающей стороны
+type Role string
+
+const (
+	// RoleSupport — сотрудники поддержки, читают заказы
+	RoleSupport Role = "support"
+	// RoleService — внутренние сервисы, читают заказы
+	RoleService Role = "service"
+	// RoleAdmin — администраторы, кроме чтения управляют кешем
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole проверяет, что роль известна сервису
+func ParseRole(value string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	switch role {
+	case RoleSupport, RoleService, RoleAdmin:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unknown role %q", value)
+	}
+}
+
+// Credentials — учетные данные из запроса
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+// Empty сообщает, что учетных данных в запросе нет
+func (c Credentials) Empty() bool {
+	return c.APIKey == "" && c.BearerToken == ""
+}
+
+// Principal — аутентифицированная вызывающая сторона
+type Principal struct {
+	Subject string
+	// Method — способ аутентификации: api_key или jwt
+	Method string
+	Roles  []Role
+}
+
+// HasAnyRole проверяет, что у вызывающей стороны есть хотя бы одна из ролей
+func (p *Principal) HasAnyRole(roles ...Role) bool {
+	if p == nil {
+		return false
+	}
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// String возвращает идентификатор для журналов аудита
+func (p *Principal) String() string {
+	if p == nil {
+		return "anonymous"
+	}
+	return p.Method + ":" + p.Subject
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TTLPolicy выбирает время жизни элемента по возрасту заказа: свежие заказы еще
+// меняются и живут в кэше недолго, старые практически неизменны и хранятся дольше
+type TTLPolicy struct {
+	Recent       time.Duration // TTL заказов моложе RecentWindow
+	Archive      time.Duration // TTL остальных заказов
+	RecentWindow time.Duration // 0 — все заказы считаются свежими
+}
+
+// For возвращает TTL для заказа, созданного в момент dateCreated; 0 — TTL кэша по умолчанию
+func (p TTLPolicy) For(dateCreated time.Time) time.Duration {
+	if p.RecentWindow <= 0 || dateCreated.IsZero() || time.Since(dateCreated) < p.RecentWindow {
+		return p.Recent
+	}
+	return p.Archive
+}
+
+// TTLPolicyVar хранит политику TTL, которую можно заменить на лету.
+// Нулевое значение готово к использованию и соответствует TTLPolicy{}.
+type TTLPolicyVar struct {
+	policy atomic.Pointer[TTLPolicy]
+}
+
+// NewTTLPolicyVar создает хранилище с начальной политикой
+func NewTTLPolicyVar(policy TTLPolicy) *TTLPolicyVar {
+	v := &TTLPolicyVar{}
+	v.Store(policy)
+	return v
+}
+
+// Load возвращает текущую политику
+func (v *TTLPolicyVar) Load() TTLPolicy {
+	if policy := v.policy.Load(); policy != nil {
+		return *policy
+	}
+	return TTLPolicy{}
+}
+
+// Store заменяет политику
+func (v *TTLPolicyVar) Store(policy TTLPolicy) {
+	v.policy.Store(&policy)
+}
+
+// For возвращает TTL по текущей политике
+func (v *TTLPolicyVar) For(dateCreated time.Time) time.Duration {
+	return v.Load().For(dateCreated)
+}
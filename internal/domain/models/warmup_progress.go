@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// WarmupProgress — состояние текущего или последнего прогрева кеша
+type WarmupProgress struct {
+	Running    bool      `json:"running"`
+	Rebuild    bool      `json:"rebuild"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Batches    int64     `json:"batches"`
+	Loaded     int64     `json:"loaded"`
+	Failed     int64     `json:"failed"`
+	LastError  string    `json:"last_error,omitempty"`
+}
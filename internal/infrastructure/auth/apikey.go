@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"order-service/internal/domain/models"
+)
+
+// APIKey — статический ключ доступа и роли его владельца
+type APIKey struct {
+	Name  string        `json:"name"`
+	Key   string        `json:"key"`
+	Roles []models.Role `json:"roles"`
+}
+
+// APIKeyAuthenticator проверяет статические ключи из заголовка X-API-Key или Authorization: Bearer
+type APIKeyAuthenticator struct {
+	// Ключи хранятся по хешу, чтобы сравнение не зависело от длины совпавшего префикса
+	keys map[[sha256.Size]byte]*models.Principal
+}
+
+func NewAPIKeyAuthenticator(keys []APIKey) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{keys: make(map[[sha256.Size]byte]*models.Principal, len(keys))}
+	for _, key := range keys {
+		if key.Name == "" || key.Key == "" {
+			return nil, fmt.Errorf("api key must have a name and a value")
+		}
+		if len(key.Roles) == 0 {
+			return nil, fmt.Errorf("api key %q has no roles", key.Name)
+		}
+		for _, role := range key.Roles {
+			if _, err := models.ParseRole(string(role)); err != nil {
+				return nil, fmt.Errorf("api key %q: %w", key.Name, err)
+			}
+		}
+
+		hash := sha256.Sum256([]byte(key.Key))
+		if _, exists := a.keys[hash]; exists {
+			return nil, fmt.Errorf("api key %q duplicates another key", key.Name)
+		}
+		a.keys[hash] = &models.Principal{Subject: key.Name, Method: "api_key", Roles: key.Roles}
+	}
+	return a, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(_ context.Context, credentials models.Credentials) (*models.Principal, error) {
+	key := credentials.APIKey
+	if key == "" {
+		// Bearer-токен вида JWT проверяет JWTAuthenticator
+		if credentials.BearerToken == "" || looksLikeJWT(credentials.BearerToken) {
+			return nil, models.ErrNoCredentials
+		}
+		key = credentials.BearerToken
+	}
+
+	principal, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, models.ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+// ParseAPIKeys разбирает список ключей вида "name:role1|role2:key,..."
+func ParseAPIKeys(spec string) ([]APIKey, error) {
+	var keys []APIKey
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid api key entry: expected name:roles:key")
+		}
+
+		key := APIKey{Name: parts[0], Key: parts[2]}
+		for _, value := range strings.Split(parts[1], "|") {
+			role, err := models.ParseRole(value)
+			if err != nil {
+				return nil, fmt.Errorf("api key %q: %w", key.Name, err)
+			}
+			key.Roles = append(key.Roles, role)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParseRouteRoles разбирает роли маршрутов вида "GET /orders/{uid}=support|admin;..."
+func ParseRouteRoles(spec string) (map[string][]models.Role, error) {
+	routeRoles := make(map[string][]models.Role)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, roles, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid route roles entry %q: expected pattern=roles", entry)
+		}
+		pattern = strings.TrimSpace(pattern)
+
+		for _, value := range strings.Split(roles, "|") {
+			role, err := models.ParseRole(value)
+			if err != nil {
+				return nil, fmt.Errorf("route %q: %w", pattern, err)
+			}
+			routeRoles[pattern] = append(routeRoles[pattern], role)
+		}
+	}
+	return routeRoles, nil
+}
+
+// LoadAPIKeysFile читает ключи из JSON-файла: [{"name": ..., "key": ..., "roles": [...]}]
+func LoadAPIKeysFile(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api keys file: %w", err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse api keys file %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"order-service/internal/domain/models"
+	"order-service/pkg/interfaces"
+)
+
+// Chain опрашивает аутентификаторы по порядку до первого, которому подошли учетные данные
+type Chain []interfaces.Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, credentials models.Credentials) (*models.Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, credentials)
+		if errors.Is(err, models.ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, models.ErrNoCredentials
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal сохраняет вызывающую сторону в контексте запроса
+func ContextWithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext возвращает вызывающую сторону; nil для анонимных запросов
+func PrincipalFromContext(ctx context.Context) *models.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*models.Principal)
+	return principal
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"order-service/internal/domain/models"
+)
+
+// JWTOptions задает требования к токенам
+type JWTOptions struct {
+	// Issuer и Audience проверяются, если заданы
+	Issuer   string
+	Audience string
+	// RolesClaim — claim со списком ролей; по умолчанию "roles"
+	RolesClaim string
+	// Leeway — допустимое расхождение часов
+	Leeway time.Duration
+}
+
+// JWTAuthenticator проверяет подпись JWT ключами из локального JWKS
+type JWTAuthenticator struct {
+	keys   map[string]crypto.PublicKey
+	opts   JWTOptions
+	parser *jwt.Parser
+}
+
+func NewJWTAuthenticator(keys map[string]crypto.PublicKey, opts JWTOptions) (*JWTAuthenticator, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks contains no usable keys")
+	}
+	if opts.RolesClaim == "" {
+		opts.RolesClaim = "roles"
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &JWTAuthenticator{keys: keys, opts: opts, parser: jwt.NewParser(parserOpts...)}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(_ context.Context, credentials models.Credentials) (*models.Principal, error) {
+	if credentials.BearerToken == "" || !looksLikeJWT(credentials.BearerToken) {
+		return nil, models.ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(credentials.BearerToken, claims, a.keyFunc); err != nil {
+		slog.Warn("JWT verification failed", "error", err)
+		return nil, models.ErrInvalidCredentials
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	return &models.Principal{Subject: subject, Method: "jwt", Roles: rolesFromClaim(claims[a.opts.RolesClaim])}, nil
+}
+
+// keyFunc выбирает ключ по kid; без kid подходит только единственный ключ JWKS
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, nil
+		}
+	}
+
+	key, ok := a.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// rolesFromClaim принимает массив ролей или строку через пробел; чужие роли пропускаются
+func rolesFromClaim(claim any) []models.Role {
+	var values []string
+	switch v := claim.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var roles []models.Role
+	for _, value := range values {
+		if role, err := models.ParseRole(value); err == nil {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKSFile читает открытые ключи RSA, EC и Ed25519 из файла JWKS
+func LoadJWKSFile(path string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS разбирает набор ключей; ключи не для подписи пропускаются.
+// Если ключей для подписи несколько, у каждого должен быть свой kid: по нему выбирается ключ токена.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse jwks: %w", err)
+	}
+
+	signing := make([]jsonWebKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use == "" || jwk.Use == "sig" {
+			signing = append(signing, jwk)
+		}
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(signing))
+	for _, jwk := range signing {
+		if jwk.Kid == "" && len(signing) > 1 {
+			return nil, fmt.Errorf("jwks key without kid: kid is required when the set has more than one key")
+		}
+		if _, ok := keys[jwk.Kid]; ok {
+			return nil, fmt.Errorf("jwks key %q: duplicate kid", jwk.Kid)
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
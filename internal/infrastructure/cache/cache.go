@@ -2,8 +2,12 @@ package cache
 
 import (
 	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"order-service/internal/domain/models"
 )
 
 // Cache представляет реализацию кэша в памяти
@@ -11,14 +15,24 @@ type Cache struct {
 	mu    sync.RWMutex
 	items map[string]cacheItem
 	ttl   time.Duration
+	// maxEntries ограничивает число элементов; 0 — без ограничения
+	maxEntries int
+
+	hits   atomic.Int64
+	misses atomic.Int64
 }
 
 type cacheItem struct {
 	data      []byte
 	createdAt time.Time
+	expiresAt time.Time // нулевое значение — элемент бессрочный
+}
+
+func (i cacheItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
 }
 
-// NewCache создает новый экземпляр кэша с TTL
+// NewCache создает новый экземпляр кэша с TTL по умолчанию
 func NewCache(ttl time.Duration) *Cache {
 	cache := &Cache{
 		items: make(map[string]cacheItem),
@@ -31,16 +45,77 @@ func NewCache(ttl time.Duration) *Cache {
 	return cache
 }
 
-// Set добавляет ключ и данные в кэш
-func (c *Cache) Set(key string, data []byte) {
+// SetTTL меняет TTL по умолчанию для новых элементов; существующие доживают со своим TTL
+func (c *Cache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+}
+
+// SetMaxEntries ограничивает число элементов; при уменьшении лишние элементы вытесняются сразу
+func (c *Cache) SetMaxEntries(maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = maxEntries
+	c.evict(0)
+}
+
+// Set добавляет ключ и данные в кэш; ttl <= 0 означает TTL по умолчанию
+func (c *Cache) Set(key string, data []byte, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.items[key] = cacheItem{
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+
+	now := time.Now()
+	item := cacheItem{
 		data:      data,
-		createdAt: time.Now(),
+		createdAt: now,
+	}
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+
+	if _, exists := c.items[key]; !exists {
+		c.evict(1)
+	}
+	c.items[key] = item
+	slog.Info("Cache updated", "key", key, "ttl", ttl)
+}
+
+// evict освобождает место под reserve новых элементов: сначала удаляет устаревшие,
+// затем самые старые. Вызывается под блокировкой записи.
+func (c *Cache) evict(reserve int) {
+	if c.maxEntries <= 0 || len(c.items)+reserve <= c.maxEntries {
+		return
+	}
+
+	now := time.Now()
+	for key, item := range c.items {
+		if item.expired(now) {
+			delete(c.items, key)
+		}
+	}
+
+	evicted := 0
+	for len(c.items) > 0 && len(c.items)+reserve > c.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, item := range c.items {
+			if oldestKey == "" || item.createdAt.Before(oldest) {
+				oldestKey, oldest = key, item.createdAt
+			}
+		}
+		delete(c.items, oldestKey)
+		evicted++
+	}
+	if evicted > 0 {
+		slog.Debug("Cache entries evicted", "evicted", evicted, "max_entries", c.maxEntries)
 	}
-	slog.Info("Cache updated", "key", key)
 }
 
 // Get получает данные из кэша
@@ -50,15 +125,18 @@ func (c *Cache) Get(key string) ([]byte, bool) {
 
 	item, found := c.items[key]
 	if !found {
+		c.misses.Add(1)
 		return nil, false
 	}
 
 	// Проверка TTL
-	if c.ttl > 0 && time.Since(item.createdAt) > c.ttl {
+	if item.expired(time.Now()) {
 		slog.Debug("Cache item expired", "key", key)
+		c.misses.Add(1)
 		return nil, false
 	}
 
+	c.hits.Add(1)
 	return item.data, true
 }
 
@@ -73,7 +151,7 @@ func (c *Cache) Has(key string) bool {
 	}
 
 	// Проверка TTL
-	if c.ttl > 0 && time.Since(item.createdAt) > c.ttl {
+	if item.expired(time.Now()) {
 		slog.Debug("Cache item expired", "key", key)
 		return false
 	}
@@ -91,24 +169,98 @@ func (c *Cache) Delete(key string) {
 	slog.Info("Removed from cache", "key", key)
 }
 
-// PrintContent выводит содержимое кэша в лог
-func (c *Cache) PrintContent() {
+// DeletePrefix удаляет все ключи с указанным префиксом и возвращает их число
+func (c *Cache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+			removed++
+		}
+	}
+	slog.Info("Removed from cache by prefix", "prefix", prefix, "removed", removed)
+
+	return removed
+}
+
+// Flush очищает кэш полностью
+func (c *Cache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := len(c.items)
+	c.items = make(map[string]cacheItem)
+	slog.Info("Cache flushed", "removed", removed)
+}
+
+// Inspect возвращает возраст, размер и оставшийся TTL элемента
+func (c *Cache) Inspect(key string) (models.CacheEntryInfo, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	slog.Info("Current cache content", "count", len(c.items))
+	item, found := c.items[key]
+	if !found {
+		return models.CacheEntryInfo{}, false
+	}
+
+	now := time.Now()
+	if item.expired(now) {
+		return models.CacheEntryInfo{}, false
+	}
+
+	age := now.Sub(item.createdAt)
+	ttl := time.Duration(-1)
+	if !item.expiresAt.IsZero() {
+		ttl = item.expiresAt.Sub(now)
+	}
+
+	return models.CacheEntryInfo{
+		Key:       key,
+		Size:      len(item.data),
+		CreatedAt: item.createdAt,
+		Age:       age,
+		TTL:       ttl,
+	}, true
+}
+
+// Stats возвращает статистику кэша: число и объем элементов, попадания и самый старый элемент
+func (c *Cache) Stats() models.CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := models.CacheStats{
+		Backend: "memory",
+		Entries: int64(len(c.items)),
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+	}
 	for key, item := range c.items {
-		slog.Debug("Cache item", "key", key, "age", time.Since(item.createdAt))
+		stats.Bytes += int64(len(key) + len(item.data))
+		if stats.OldestEntry.IsZero() || item.createdAt.Before(stats.OldestEntry) {
+			stats.OldestEntry = item.createdAt
+		}
 	}
+	stats.ComputeHitRatio()
+
+	return stats
 }
 
 // Очистка устаревших элементов
 func (c *Cache) startCleanupTask() {
-	if c.ttl <= 0 {
-		return // Если TTL не установлен, не запускаем очистку
+	// Элементы могут получать собственный TTL, поэтому очистка нужна даже без TTL по умолчанию
+	c.mu.RLock()
+	ttl := c.ttl
+	c.mu.RUnlock()
+
+	interval := time.Minute
+	if ttl > 0 && ttl/2 < interval {
+		interval = ttl / 2
 	}
 
-	ticker := time.NewTicker(c.ttl / 2)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for range ticker.C {
@@ -120,11 +272,11 @@ func (c *Cache) cleanupExpired() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Исправление: удалена неиспользуемая переменная now
+	now := time.Now()
 	expiredKeys := 0
 
 	for key, item := range c.items {
-		if time.Since(item.createdAt) > c.ttl {
+		if item.expired(now) {
 			delete(c.items, key)
 			expiredKeys++
 		}
@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"order-service/internal/domain/models"
+)
+
+// Поля хеша, в котором хранится элемент: данные и время записи (Unix, мс)
+const (
+	redisFieldData      = "data"
+	redisFieldCreatedAt = "created_at"
+)
+
+// RedisCache — реализация CacheRepository поверх Redis, общая для всех реплик сервиса.
+// Элемент хранится хешем с данными и временем записи, чтобы Inspect знал возраст при любом TTL.
+type RedisCache struct {
+	client    redis.UniversalClient
+	prefix    string
+	ttl       atomic.Int64 // time.Duration; меняется на лету через SetTTL
+	opTimeout time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewRedisCache создает кэш в Redis; все ключи получают префикс prefix
+func NewRedisCache(client redis.UniversalClient, prefix string, ttl, opTimeout time.Duration) *RedisCache {
+	if opTimeout <= 0 {
+		opTimeout = time.Second
+	}
+
+	c := &RedisCache{
+		client:    client,
+		prefix:    prefix,
+		opTimeout: opTimeout,
+	}
+	c.ttl.Store(int64(ttl))
+	return c
+}
+
+// SetTTL меняет TTL по умолчанию для новых ключей
+func (c *RedisCache) SetTTL(ttl time.Duration) {
+	c.ttl.Store(int64(ttl))
+}
+
+// Ping проверяет доступность Redis
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Set добавляет ключ и данные в кэш; ttl <= 0 означает TTL по умолчанию,
+// а при нулевом TTL по умолчанию ключ хранится бессрочно, как и в локальном кеше
+func (c *RedisCache) Set(key string, data []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = time.Duration(c.ttl.Load())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	// DEL заменяет элемент целиком, в том числе записанный строкой в прежнем формате
+	pipe := c.client.TxPipeline()
+	pipe.Del(ctx, c.prefix+key)
+	pipe.HSet(ctx, c.prefix+key, redisFieldData, data, redisFieldCreatedAt, time.Now().UnixMilli())
+	// PEXPIRE с нулем удалил бы только что записанный ключ
+	if ttl > 0 {
+		pipe.PExpire(ctx, c.prefix+key, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Error("Failed to set key in Redis", "error", err, "key", key)
+		return
+	}
+	slog.Info("Cache updated", "key", key, "ttl", ttl, "backend", "redis")
+}
+
+// Get получает данные из кэша; ошибки Redis считаются промахом
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	data, err := c.client.HGet(ctx, c.prefix+key, redisFieldData).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Error("Failed to get key from Redis", "error", err, "key", key)
+		}
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return data, true
+}
+
+// GetWithTTL получает данные вместе с оставшимся TTL за один запрос к Redis;
+// TTL отрицательный, если ключ бессрочный
+func (c *RedisCache) GetWithTTL(key string) ([]byte, time.Duration, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	pipe := c.client.Pipeline()
+	get := pipe.HGet(ctx, c.prefix+key, redisFieldData)
+	pttl := pipe.PTTL(ctx, c.prefix+key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Error("Failed to get key from Redis", "error", err, "key", key)
+		}
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+
+	data, _ := get.Bytes()
+	c.hits.Add(1)
+	return data, pttl.Val(), true
+}
+
+// Has проверяет наличие ключа в кэше
+func (c *RedisCache) Has(key string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	n, err := c.client.Exists(ctx, c.prefix+key).Result()
+	if err != nil {
+		slog.Error("Failed to check key in Redis", "error", err, "key", key)
+		return false
+	}
+
+	return n > 0
+}
+
+// Delete удаляет ключ из кэша
+func (c *RedisCache) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.prefix+key).Err(); err != nil {
+		slog.Error("Failed to delete key from Redis", "error", err, "key", key)
+		return
+	}
+	slog.Info("Removed from cache", "key", key, "backend", "redis")
+}
+
+// DeletePrefix удаляет все ключи с указанным префиксом и возвращает их число
+func (c *RedisCache) DeletePrefix(prefix string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	removed := 0
+	iter := c.client.Scan(ctx, 0, c.prefix+escapeGlob(prefix)+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+			slog.Error("Failed to delete key from Redis", "error", err, "key", iter.Val())
+			continue
+		}
+		removed++
+	}
+	if err := iter.Err(); err != nil {
+		slog.Error("Failed to scan Redis keys", "error", err, "prefix", prefix)
+	}
+	slog.Info("Removed from cache by prefix", "prefix", prefix, "removed", removed, "backend", "redis")
+
+	return removed
+}
+
+// escapeGlob экранирует символы шаблона SCAN MATCH, чтобы префикс сопоставлялся буквально
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Flush удаляет все ключи кэша; чужие ключи в той же базе Redis не затрагиваются
+func (c *RedisCache) Flush() {
+	c.DeletePrefix("")
+}
+
+// Inspect возвращает размер, время записи и оставшийся TTL ключа
+func (c *RedisCache) Inspect(key string) (models.CacheEntryInfo, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	pipe := c.client.Pipeline()
+	strlen := pipe.Do(ctx, "HSTRLEN", c.prefix+key, redisFieldData)
+	createdAt := pipe.HGet(ctx, c.prefix+key, redisFieldCreatedAt)
+	pttl := pipe.PTTL(ctx, c.prefix+key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		slog.Error("Failed to inspect key in Redis", "error", err, "key", key)
+		return models.CacheEntryInfo{}, false
+	}
+
+	// PTTL возвращает -2 для отсутствующего ключа и -1 для бессрочного
+	ttl := pttl.Val()
+	if ttl == -2 {
+		return models.CacheEntryInfo{}, false
+	}
+
+	size, _ := strlen.Int64()
+	info := models.CacheEntryInfo{
+		Key:  key,
+		Size: int(size),
+		TTL:  ttl,
+	}
+	if millis, err := createdAt.Int64(); err == nil {
+		info.CreatedAt = time.UnixMilli(millis)
+		info.Age = max(time.Since(info.CreatedAt), 0)
+	}
+
+	return info, true
+}
+
+// Stats возвращает число ключей с префиксом кэша, объем памяти Redis и счетчики попаданий этой реплики
+func (c *RedisCache) Stats() models.CacheStats {
+	stats := models.CacheStats{
+		Backend: "redis",
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+	}
+	stats.ComputeHitRatio()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		stats.Entries++
+	}
+	if err := iter.Err(); err != nil {
+		slog.Error("Failed to count Redis keys", "error", err)
+	}
+
+	info, err := c.client.Info(ctx, "memory").Result()
+	if err != nil {
+		slog.Error("Failed to read Redis memory info", "error", err)
+		return stats
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if used, ok := strings.CutPrefix(line, "used_memory:"); ok {
+			stats.Bytes, _ = strconv.ParseInt(strings.TrimSpace(used), 10, 64)
+			break
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"order-service/pkg/interfaces"
+)
+
+// RefreshAheadCache перезагружает в фоне элементы, которые читают незадолго до истечения TTL.
+// Пока идет перезагрузка, клиентам отдаются текущие данные, поэтому популярные ключи
+// не пропадают из кэша и не вызывают одновременный поток запросов в БД.
+type RefreshAheadCache struct {
+	interfaces.CacheRepository
+
+	loader  interfaces.CacheLoader
+	window  time.Duration
+	timeout time.Duration
+
+	mu         sync.Mutex
+	refreshing map[string]struct{}
+	wg         sync.WaitGroup
+}
+
+// NewRefreshAheadCache оборачивает кэш; перезагрузка запускается, когда до истечения TTL остается меньше window
+func NewRefreshAheadCache(inner interfaces.CacheRepository, loader interfaces.CacheLoader, window, timeout time.Duration) *RefreshAheadCache {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &RefreshAheadCache{
+		CacheRepository: inner,
+		loader:          loader,
+		window:          window,
+		timeout:         timeout,
+		refreshing:      make(map[string]struct{}),
+	}
+}
+
+// Get возвращает данные из кэша и при необходимости запускает фоновую перезагрузку
+func (c *RefreshAheadCache) Get(key string) ([]byte, bool) {
+	data, found := c.CacheRepository.Get(key)
+	if !found {
+		return nil, false
+	}
+
+	if info, ok := c.CacheRepository.Inspect(key); ok && info.TTL >= 0 && info.TTL < c.window {
+		c.refresh(key)
+	}
+
+	return data, true
+}
+
+// refresh запускает перезагрузку ключа, если она еще не идет
+func (c *RefreshAheadCache) refresh(key string) {
+	c.mu.Lock()
+	if _, busy := c.refreshing[key]; busy {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = struct{}{}
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+
+		data, ttl, err := c.loader(ctx, key)
+		if err != nil {
+			// Оставляем текущие данные до истечения их TTL
+			slog.Error("Cache refresh-ahead failed", "error", err, "key", key)
+			return
+		}
+
+		c.CacheRepository.Set(key, data, ttl)
+		slog.Debug("Cache entry refreshed ahead of expiry", "key", key, "ttl", ttl)
+	}()
+}
+
+// Wait дожидается завершения запущенных перезагрузок
+func (c *RefreshAheadCache) Wait() {
+	c.wg.Wait()
+}
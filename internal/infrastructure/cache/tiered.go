@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	"order-service/internal/domain/models"
+	"order-service/pkg/interfaces"
+)
+
+// TieredCache — двухуровневый кэш: локальный кэш реплики перед общим удаленным
+type TieredCache struct {
+	local    interfaces.CacheRepository
+	remote   interfaces.CacheRepository
+	localTTL atomic.Int64 // time.Duration; меняется на лету через SetLocalTTL
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// ttlGetter — уровень кэша, который отдает данные вместе с оставшимся TTL за один запрос
+type ttlGetter interface {
+	GetWithTTL(key string) ([]byte, time.Duration, bool)
+}
+
+// NewTieredCache создает двухуровневый кэш; localTTL ограничивает время жизни копий в локальном уровне
+func NewTieredCache(local, remote interfaces.CacheRepository, localTTL time.Duration) *TieredCache {
+	c := &TieredCache{
+		local:  local,
+		remote: remote,
+	}
+	c.localTTL.Store(int64(localTTL))
+	return c
+}
+
+// SetLocalTTL меняет предельное время жизни копий в локальном уровне
+func (c *TieredCache) SetLocalTTL(localTTL time.Duration) {
+	c.localTTL.Store(int64(localTTL))
+}
+
+// Set записывает данные в общий кэш, затем в локальный; локальная копия живет не дольше localTTL
+func (c *TieredCache) Set(key string, data []byte, ttl time.Duration) {
+	c.remote.Set(key, data, ttl)
+	c.local.Set(key, data, c.localTTLFor(ttl))
+}
+
+// localTTLFor ограничивает время жизни локальной копии временем жизни элемента в общем кэше
+func (c *TieredCache) localTTLFor(ttl time.Duration) time.Duration {
+	localTTL := time.Duration(c.localTTL.Load())
+	if ttl > 0 && (localTTL <= 0 || ttl < localTTL) {
+		localTTL = ttl
+	}
+	return localTTL
+}
+
+// Get ищет ключ в локальном кэше, при промахе — в общем, подтягивая найденное в локальный.
+// Локальная копия не переживает элемент общего кэша.
+func (c *TieredCache) Get(key string) ([]byte, bool) {
+	if data, found := c.local.Get(key); found {
+		c.hits.Add(1)
+		return data, true
+	}
+
+	data, remaining, found := c.getRemote(key)
+	if !found {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.local.Set(key, data, c.localTTLFor(remaining))
+	c.hits.Add(1)
+	return data, true
+}
+
+// getRemote читает ключ из общего кэша вместе с оставшимся TTL
+func (c *TieredCache) getRemote(key string) ([]byte, time.Duration, bool) {
+	if getter, ok := c.remote.(ttlGetter); ok {
+		return getter.GetWithTTL(key)
+	}
+
+	data, found := c.remote.Get(key)
+	if !found {
+		return nil, 0, false
+	}
+	info, _ := c.remote.Inspect(key)
+	return data, info.TTL, true
+}
+
+// Has проверяет наличие ключа на любом из уровней
+func (c *TieredCache) Has(key string) bool {
+	return c.local.Has(key) || c.remote.Has(key)
+}
+
+// Delete удаляет ключ с обоих уровней
+func (c *TieredCache) Delete(key string) {
+	c.remote.Delete(key)
+	c.local.Delete(key)
+}
+
+// DeletePrefix удаляет ключи с префиксом с обоих уровней и возвращает число удаленных из общего кэша
+func (c *TieredCache) DeletePrefix(prefix string) int {
+	removed := c.remote.DeletePrefix(prefix)
+	c.local.DeletePrefix(prefix)
+	return removed
+}
+
+// Flush очищает оба уровня
+func (c *TieredCache) Flush() {
+	c.remote.Flush()
+	c.local.Flush()
+}
+
+// Inspect возвращает сведения об элементе из общего кэша, а при его отсутствии — из локального
+func (c *TieredCache) Inspect(key string) (models.CacheEntryInfo, bool) {
+	if info, found := c.remote.Inspect(key); found {
+		return info, true
+	}
+	return c.local.Inspect(key)
+}
+
+// Stats возвращает общие счетчики попаданий и статистику каждого уровня
+func (c *TieredCache) Stats() models.CacheStats {
+	localStats := c.local.Stats()
+	remoteStats := c.remote.Stats()
+
+	stats := models.CacheStats{
+		Backend:     "tiered",
+		Entries:     remoteStats.Entries,
+		Bytes:       remoteStats.Bytes,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		OldestEntry: remoteStats.OldestEntry,
+		Layers:      []models.CacheStats{localStats, remoteStats},
+	}
+	stats.ComputeHitRatio()
+
+	return stats
+}
+
+// Local возвращает локальный уровень кэша
+func (c *TieredCache) Local() interfaces.CacheRepository {
+	return c.local
+}
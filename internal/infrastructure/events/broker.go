@@ -0,0 +1,127 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"order-service/internal/domain/models"
+)
+
+type subscriber struct {
+	ch     chan models.OrderEvent
+	filter models.OrderEventFilter
+}
+
+// Broker — внутрипроцессная шина событий о заказах.
+// Публикация не блокируется: подписчик с переполненным буфером отключается
+// и может переподключиться, дочитав пропущенное из истории по ID последнего события.
+type Broker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []models.OrderEvent // кольцевой буфер последних событий
+	historyNext int
+	historySize int
+	subscribers map[*subscriber]struct{}
+}
+
+// NewBroker создает шину, хранящую historySize последних событий для возобновления подписок
+func NewBroker(historySize int) *Broker {
+	if historySize < 0 {
+		historySize = 0
+	}
+
+	return &Broker{
+		history:     make([]models.OrderEvent, 0, historySize),
+		historySize: historySize,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish назначает событию ID и рассылает его подписчикам, чьи фильтры оно проходит
+func (b *Broker) Publish(event models.OrderEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	b.remember(event)
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(event.Order) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			slog.Warn("Order event subscriber is too slow, disconnecting", "event_id", event.ID)
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+func (b *Broker) remember(event models.OrderEvent) {
+	if b.historySize == 0 {
+		return
+	}
+
+	if len(b.history) < b.historySize {
+		b.history = append(b.history, event)
+		return
+	}
+	b.history[b.historyNext] = event
+	b.historyNext = (b.historyNext + 1) % b.historySize
+}
+
+// Subscribe регистрирует подписчика. Если afterID > 0, сначала в канал попадают
+// сохраненные в истории события с ID больше afterID.
+func (b *Broker) Subscribe(filter models.OrderEventFilter, afterID uint64, buffer int) (<-chan models.OrderEvent, func()) {
+	if buffer <= 0 {
+		buffer = 1
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []models.OrderEvent
+	if afterID > 0 {
+		// История упорядочена начиная с самого старого элемента
+		for i := 0; i < len(b.history); i++ {
+			event := b.history[(b.historyNext+i)%len(b.history)]
+			if event.ID > afterID && filter.Match(event.Order) {
+				replay = append(replay, event)
+			}
+		}
+		if len(b.history) > 0 && b.history[b.historyNext%len(b.history)].ID > afterID+1 {
+			slog.Warn("Order event history does not cover resume point, some events are lost",
+				"after_id", afterID)
+		}
+	}
+
+	sub := &subscriber{
+		ch:     make(chan models.OrderEvent, buffer+len(replay)),
+		filter: filter,
+	}
+	for _, event := range replay {
+		sub.ch <- event
+	}
+	b.subscribers[sub] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		// Канал мог быть уже закрыт при отключении медленного подписчика
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+
+	return sub.ch, unsubscribe
+}
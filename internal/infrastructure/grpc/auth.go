@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/auth"
+	orderv1 "order-service/pkg/api/order/v1"
+	"order-service/pkg/interfaces"
+)
+
+// Методы OrderService доступны тем же ролям, что и чтение заказов через HTTP;
+// health и reflection остаются открытыми
+var readerRoles = []models.Role{models.RoleSupport, models.RoleService, models.RoleAdmin}
+
+// SetAuthenticator включает аутентификацию вызовов OrderService
+func (s *OrderGRPCServer) SetAuthenticator(authenticator interfaces.Authenticator) {
+	s.authenticator = authenticator
+}
+
+// SetPIIPolicy задает роли, которым персональные данные заказов отдаются без маскирования
+func (s *OrderGRPCServer) SetPIIPolicy(policy models.PIIPolicy) {
+	s.service.piiPolicy = policy
+}
+
+func (s *OrderGRPCServer) unaryAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *OrderGRPCServer) streamAuthInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize проверяет учетные данные из метаданных authorization или x-api-key
+func (s *OrderGRPCServer) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if s.authenticator == nil || !strings.HasPrefix(fullMethod, "/"+orderv1.OrderService_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+
+	principal, err := s.authenticator.Authenticate(ctx, credentialsFromMetadata(ctx))
+	if errors.Is(err, models.ErrNoCredentials) {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	} else if err != nil {
+		slog.WarnContext(ctx, "Unauthenticated gRPC call", "method", fullMethod)
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	if !principal.HasAnyRole(readerRoles...) {
+		slog.WarnContext(ctx, "Forbidden gRPC call", "method", fullMethod, "principal", principal.String())
+		return nil, status.Error(codes.PermissionDenied, "insufficient role")
+	}
+
+	return auth.ContextWithPrincipal(ctx, principal), nil
+}
+
+func credentialsFromMetadata(ctx context.Context) models.Credentials {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var credentials models.Credentials
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		credentials.APIKey = values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if token, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+			credentials.BearerToken = strings.TrimSpace(token)
+		}
+	}
+	return credentials
+}
+
+// contextStream подменяет контекст потока, например контекстом с вызывающей стороной
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"order-service/internal/domain/models"
+	orderv1 "order-service/pkg/api/order/v1"
+)
+
+func toProtoOrder(order *models.Order) *orderv1.Order {
+	items := make([]*orderv1.Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, &orderv1.Item{
+			ChrtId:      item.ChrtID,
+			TrackNumber: item.TrackNumber,
+			Price:       item.Price,
+			Rid:         item.Rid,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  item.TotalPrice,
+			NmId:        item.NmID,
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+
+	result := &orderv1.Order{
+		OrderUid:    order.OrderUID,
+		TrackNumber: order.TrackNumber,
+		Entry:       order.Entry,
+		Delivery: &orderv1.Delivery{
+			Name:    order.Delivery.Name,
+			Phone:   order.Delivery.Phone,
+			Zip:     order.Delivery.Zip,
+			City:    order.Delivery.City,
+			Address: order.Delivery.Address,
+			Region:  order.Delivery.Region,
+			Email:   order.Delivery.Email,
+		},
+		Payment: &orderv1.Payment{
+			Transaction:  order.Payment.Transaction,
+			RequestId:    order.Payment.RequestID,
+			Currency:     order.Payment.Currency,
+			Provider:     order.Payment.Provider,
+			Amount:       int64(order.Payment.Amount),
+			PaymentDt:    order.Payment.PaymentDt,
+			Bank:         order.Payment.Bank,
+			DeliveryCost: order.Payment.DeliveryCost,
+			GoodsTotal:   order.Payment.GoodsTotal,
+			CustomFee:    order.Payment.CustomFee,
+		},
+		Items:             items,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerId:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.Shardkey,
+		SmId:              int64(order.SmID),
+		OofShard:          order.OofShard,
+	}
+	if !order.DateCreated.IsZero() {
+		result.DateCreated = timestamppb.New(order.DateCreated)
+	}
+
+	return result
+}
+
+func toProtoEvent(event models.OrderEvent) *orderv1.OrderEvent {
+	eventType := orderv1.OrderEventType_ORDER_EVENT_TYPE_UNSPECIFIED
+	switch event.Type {
+	case models.OrderCreated:
+		eventType = orderv1.OrderEventType_ORDER_EVENT_TYPE_CREATED
+	case models.OrderUpdated:
+		eventType = orderv1.OrderEventType_ORDER_EVENT_TYPE_UPDATED
+	}
+
+	return &orderv1.OrderEvent{
+		Id:         event.ID,
+		Type:       eventType,
+		Order:      toProtoOrder(&event.Order),
+		OccurredAt: timestamppb.New(event.At),
+	}
+}
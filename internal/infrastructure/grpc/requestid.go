@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order-service/internal/logger"
+)
+
+const requestIDMetadata = "x-request-id"
+
+func unaryRequestIDInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+func streamRequestIDInterceptor(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: stream, ctx: withRequestID(stream.Context())})
+}
+
+// withRequestID берет идентификатор из метаданных x-request-id или создает новый
+// и возвращает его клиенту в заголовке ответа
+func withRequestID(ctx context.Context) context.Context {
+	var candidate string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			candidate = values[0]
+		}
+	}
+	requestID := logger.RequestIDOrNew(candidate)
+
+	// Ошибка возможна только вне обработчика gRPC, например в тестах с голым контекстом
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+	return logger.WithRequestID(ctx, requestID)
+}
@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	orderv1 "order-service/pkg/api/order/v1"
+	"order-service/pkg/interfaces"
+)
+
+type OrderGRPCServer struct {
+	server        *grpc.Server
+	health        *health.Server
+	service       *orderService
+	authenticator interfaces.Authenticator
+	port          int
+	isRunning     bool
+	// failures получает ошибку, если сервер перестал принимать соединения после запуска
+	failures chan error
+}
+
+func NewOrderGRPCServer(port int, orders interfaces.OrderService, events interfaces.OrderEventBus) *OrderGRPCServer {
+	s := &OrderGRPCServer{
+		health:  health.NewServer(),
+		service: newOrderService(orders, events),
+		port:    port,
+	}
+
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryRequestIDInterceptor, s.unaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(streamRequestIDInterceptor, s.streamAuthInterceptor),
+	)
+	orderv1.RegisterOrderServiceServer(s.server, s.service)
+	healthpb.RegisterHealthServer(s.server, s.health)
+	reflection.Register(s.server)
+
+	return s
+}
+
+func (s *OrderGRPCServer) Start() error {
+	if s.isRunning {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Starting gRPC server", "port", s.port)
+
+	failures := make(chan error, 1)
+	s.failures = failures
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			slog.Error("gRPC server error", "error", err)
+			failures <- fmt.Errorf("gRPC server stopped serving: %w", err)
+		}
+	}()
+
+	s.isRunning = true
+	return nil
+}
+
+// Failures возвращает канал отказов текущего запуска сервера
+func (s *OrderGRPCServer) Failures() <-chan error {
+	return s.failures
+}
+
+// Serve обслуживает запросы на переданном listener; блокируется до остановки сервера
+func (s *OrderGRPCServer) Serve(lis net.Listener) error {
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(orderv1.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+func (s *OrderGRPCServer) Shutdown(ctx context.Context) error {
+	slog.Info("gRPC server shutting down")
+
+	// Клиенты health-check узнают об остановке до закрытия соединений
+	s.health.Shutdown()
+	s.service.stopWatchers()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+		<-stopped
+	}
+
+	s.isRunning = false
+	return nil
+}
@@ -0,0 +1,205 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/auth"
+	orderv1 "order-service/pkg/api/order/v1"
+	"order-service/pkg/interfaces"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+	watchBuffer     = 64
+)
+
+// orderService реализует orderv1.OrderServiceServer поверх общего пути чтения заказов
+type orderService struct {
+	orderv1.UnimplementedOrderServiceServer
+
+	orders    interfaces.OrderService
+	events    interfaces.OrderEventBus
+	piiPolicy models.PIIPolicy
+
+	// Закрывается при остановке сервера, чтобы завершить открытые стримы WatchOrders
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newOrderService(orders interfaces.OrderService, events interfaces.OrderEventBus) *orderService {
+	return &orderService{
+		orders:    orders,
+		events:    events,
+		piiPolicy: models.DefaultPIIPolicy(),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (s *orderService) stopWatchers() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// present маскирует персональные данные заказа по роли вызывающей стороны
+func (s *orderService) present(ctx context.Context, order models.Order) *orderv1.Order {
+	masked := s.piiPolicy.Apply(auth.PrincipalFromContext(ctx), order)
+	return toProtoOrder(&masked)
+}
+
+func (s *orderService) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.GetOrderResponse, error) {
+	order, err := s.orders.Get(ctx, req.GetOrderUid())
+	if err != nil {
+		return nil, toStatus(err, req.GetOrderUid())
+	}
+
+	return &orderv1.GetOrderResponse{Order: s.present(ctx, *order)}, nil
+}
+
+func (s *orderService) BatchGetOrders(ctx context.Context, req *orderv1.BatchGetOrdersRequest) (*orderv1.BatchGetOrdersResponse, error) {
+	orders, missing, err := s.orders.GetMany(ctx, req.GetOrderUids())
+	if errors.Is(err, models.ErrBatchTooLarge) {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d order uids per request", models.MaxBatchOrders)
+	} else if err != nil {
+		return nil, toStatus(err, "")
+	}
+
+	resp := &orderv1.BatchGetOrdersResponse{
+		Orders:           make([]*orderv1.Order, 0, len(orders)),
+		MissingOrderUids: missing,
+	}
+	for i := range orders {
+		resp.Orders = append(resp.Orders, s.present(ctx, orders[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *orderService) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	query := models.OrderPageQuery{Limit: pageSize}
+	if req.GetCreatedSince() != nil {
+		query.Since = req.GetCreatedSince().AsTime()
+	}
+	if req.GetPageToken() != "" {
+		cursor, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		query.AfterCreated = cursor.Created
+		query.AfterUID = cursor.UID
+	}
+
+	page, err := s.orders.List(ctx, query)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list orders", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list orders")
+	}
+
+	resp := &orderv1.ListOrdersResponse{Orders: make([]*orderv1.Order, 0, len(page.Orders))}
+	for i := range page.Orders {
+		resp.Orders = append(resp.Orders, s.present(ctx, page.Orders[i]))
+	}
+	if page.Next != nil {
+		resp.NextPageToken = encodePageToken(pageCursor{Created: page.Next.Created, UID: page.Next.UID})
+	}
+
+	return resp, nil
+}
+
+func (s *orderService) WatchOrders(req *orderv1.WatchOrdersRequest, stream orderv1.OrderService_WatchOrdersServer) error {
+	if s.events == nil {
+		return status.Error(codes.Unavailable, "order events are not enabled")
+	}
+
+	filter := models.OrderEventFilter{
+		DeliveryServices: req.GetDeliveryServices(),
+		Regions:          req.GetRegions(),
+		Currencies:       req.GetCurrencies(),
+	}
+	events, unsubscribe := s.events.Subscribe(filter, req.GetAfterEventId(), watchBuffer)
+	defer unsubscribe()
+
+	slog.InfoContext(stream.Context(), "gRPC order watch started")
+	defer slog.InfoContext(stream.Context(), "gRPC order watch finished")
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stop:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event, ok := <-events:
+			if !ok {
+				// Клиент может переподключиться с after_event_id последнего полученного события
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow, events were dropped")
+			}
+			event.Order = s.piiPolicy.Apply(auth.PrincipalFromContext(stream.Context()), event.Order)
+			if err := stream.Send(toProtoEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus преобразует ошибки пути чтения в статусы gRPC
+func toStatus(err error, orderUID string) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidOrderUID):
+		return status.Error(codes.InvalidArgument, "invalid order uid")
+	case errors.Is(err, models.ErrOrderNotFound):
+		return status.Errorf(codes.NotFound, "order %s not found", orderUID)
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		slog.Error("Database query error", "error", err, "orderUID", orderUID)
+		return status.Error(codes.Internal, "failed to load order")
+	}
+}
+
+// pageCursor — позиция keyset-пагинации, передаваемая клиенту в непрозрачном токене
+type pageCursor struct {
+	Created time.Time `json:"c"`
+	UID     string    `json:"u"`
+}
+
+func encodePageToken(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(token string) (pageCursor, error) {
+	var cursor pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.UID == "" || cursor.Created.IsZero() {
+		return cursor, errors.New("incomplete page token")
+	}
+	return cursor, nil
+}
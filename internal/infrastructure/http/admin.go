@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/logger"
+	"order-service/pkg/interfaces"
+)
+
+// registerAdminRoutes регистрирует эндпоинты администрирования кеша, конфигурации и логирования
+func (s *OrderHTTPServer) registerAdminRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /admin/cache/stats", s.adminOnly(s.cacheStatsHandler()))
+	mux.Handle("GET /admin/cache/keys/{key}", s.adminOnly(s.cacheInspectHandler()))
+	mux.Handle("DELETE /admin/cache/keys/{key}", s.adminOnly(s.cacheEvictHandler()))
+	mux.Handle("DELETE /admin/cache/keys", s.adminOnly(s.cacheEvictPrefixHandler()))
+	mux.Handle("POST /admin/cache/flush", s.adminOnly(s.cacheFlushHandler()))
+	mux.Handle("POST /admin/cache/warmup", s.adminOnly(s.cacheWarmupHandler()))
+	mux.Handle("GET /admin/log/level", s.adminOnly(s.logLevelHandler()))
+	mux.Handle("PUT /admin/log/level", s.adminOnly(s.setLogLevelHandler()))
+
+	if s.reloader != nil {
+		mux.Handle("GET /admin/config/reload", s.adminOnly(s.configReloadStatusHandler()))
+		mux.Handle("POST /admin/config/reload", s.adminOnly(s.configReloadHandler()))
+	}
+}
+
+// SetConfigReloader включает эндпоинты перезагрузки конфигурации
+func (s *OrderHTTPServer) SetConfigReloader(reloader interfaces.ConfigReloader) {
+	s.reloader = reloader
+}
+
+// adminOnly пропускает только вызывающие стороны с ролью admin
+func (s *OrderHTTPServer) adminOnly(next http.Handler) http.Handler {
+	return s.requireRoles(adminRoles, next)
+}
+
+// Ответ с описанием элемента кеша; длительности выводятся в человекочитаемом виде
+type cacheEntryResponse struct {
+	Key       string    `json:"key"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Age       string    `json:"age"`
+	TTL       string    `json:"ttl"`
+}
+
+func newCacheEntryResponse(info models.CacheEntryInfo) cacheEntryResponse {
+	resp := cacheEntryResponse{
+		Key:       info.Key,
+		Size:      info.Size,
+		CreatedAt: info.CreatedAt,
+		Age:       info.Age.Round(time.Millisecond).String(),
+		TTL:       info.TTL.Round(time.Millisecond).String(),
+	}
+	if info.TTL < 0 {
+		resp.TTL = "none"
+	}
+	return resp
+}
+
+func (s *OrderHTTPServer) cacheStatsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := struct {
+			Cache  models.CacheStats      `json:"cache"`
+			Warmup *models.WarmupProgress `json:"warmup,omitempty"`
+		}{
+			Cache: s.cache.Stats(),
+		}
+		if s.warmer != nil {
+			progress := s.warmer.Progress()
+			resp.Warmup = &progress
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *OrderHTTPServer) cacheInspectHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+
+		info, found := s.cache.Inspect(key)
+		if !found {
+			writeProblem(w, r, http.StatusNotFound, codeCacheKeyNotFound, "Ключ не найден в кеше")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newCacheEntryResponse(info))
+	}
+}
+
+func (s *OrderHTTPServer) cacheEvictHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+
+		found := s.cache.Has(key)
+		s.cache.Delete(key)
+		s.publishInvalidation(r, models.CacheInvalidation{Keys: []string{key}, Reason: "admin_evict"})
+
+		slog.InfoContext(r.Context(), "Admin action", "action", "cache_evict", "key", key, "found", found, "principal", principalName(r), "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, map[string]any{"key": key, "evicted": found})
+	}
+}
+
+func (s *OrderHTTPServer) cacheEvictPrefixHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix := r.URL.Query().Get("prefix")
+		if prefix == "" {
+			writeProblem(w, r, http.StatusBadRequest, codePrefixRequired, "Параметр 'prefix' обязателен; для полной очистки используйте /admin/cache/flush")
+			return
+		}
+
+		removed := s.cache.DeletePrefix(prefix)
+		s.publishInvalidation(r, models.CacheInvalidation{Prefix: prefix, Reason: "admin_evict_prefix"})
+
+		slog.InfoContext(r.Context(), "Admin action", "action", "cache_evict_prefix", "prefix", prefix, "removed", removed, "principal", principalName(r), "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, map[string]any{"prefix": prefix, "evicted": removed})
+	}
+}
+
+func (s *OrderHTTPServer) cacheFlushHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.cache.Flush()
+		s.publishInvalidation(r, models.CacheInvalidation{Flush: true, Reason: "admin_flush"})
+
+		slog.InfoContext(r.Context(), "Admin action", "action", "cache_flush", "principal", principalName(r), "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, map[string]any{"flushed": true})
+	}
+}
+
+// cacheWarmupHandler запускает прогрев в фоне; с rebuild=true заказы собираются заново из таблиц
+func (s *OrderHTTPServer) cacheWarmupHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.warmer == nil {
+			writeProblem(w, r, http.StatusNotImplemented, codeWarmupNotConfigured, "Прогрев кеша не настроен")
+			return
+		}
+		rebuild := r.URL.Query().Get("rebuild") == "true"
+		done, err := s.warmer.TryStart(s.baseCtx, rebuild)
+		if errors.Is(err, models.ErrWarmupInProgress) {
+			writeProblem(w, r, http.StatusConflict, codeWarmupInProgress, "Прогрев кеша уже выполняется")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to start cache warm-up", "error", err)
+			writeProblem(w, r, http.StatusInternalServerError, codeInternal, "Не удалось запустить прогрев кеша")
+			return
+		}
+
+		// Запрос завершится раньше прогрева: пишем результат в журнал с контекстом сервера,
+		// сохранив идентификатор запроса
+		logCtx := logger.WithRequestID(s.baseCtx, logger.RequestIDFromContext(r.Context()))
+		go func() {
+			if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+				slog.ErrorContext(logCtx, "Admin-triggered cache warm-up failed", "error", err)
+			}
+		}()
+
+		slog.InfoContext(r.Context(), "Admin action", "action", "cache_warmup", "rebuild", rebuild, "principal", principalName(r), "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusAccepted, map[string]any{"started": true, "rebuild": rebuild})
+	}
+}
+
+// configReloadStatusHandler возвращает результат последней перезагрузки конфигурации
+func (s *OrderHTTPServer) configReloadStatusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, ok := s.reloader.LastReload()
+		if !ok {
+			writeJSON(w, http.StatusOK, map[string]any{"reloaded": false})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// configReloadHandler перечитывает конфигурацию и применяет изменяемые на лету параметры
+func (s *OrderHTTPServer) configReloadHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := s.reloader.Reload(models.ReloadTriggerAPI)
+
+		slog.InfoContext(r.Context(), "Admin action", "action", "config_reload", "status", result.Status, "principal", principalName(r), "remote_addr", r.RemoteAddr)
+		if result.Status == models.ReloadStatusFailed {
+			writeJSON(w, http.StatusUnprocessableEntity, result)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// publishInvalidation сообщает остальным репликам об удалении данных из кеша
+func (s *OrderHTTPServer) publishInvalidation(r *http.Request, invalidation models.CacheInvalidation) {
+	if s.publisher == nil {
+		return
+	}
+
+	if err := s.publisher.PublishInvalidation(r.Context(), invalidation); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to publish cache invalidation", "error", err, "reason", invalidation.Reason)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode JSON response", "error", err)
+	}
+}
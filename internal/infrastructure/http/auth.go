@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/auth"
+	"order-service/pkg/interfaces"
+)
+
+// Роли, которым по умолчанию доступно чтение заказов
+var readerRoles = []models.Role{models.RoleSupport, models.RoleService, models.RoleAdmin}
+
+// Эндпоинты администрирования доступны только администраторам, переопределить это нельзя
+var adminRoles = []models.Role{models.RoleAdmin}
+
+type authErrorKey struct{}
+
+// SetAuthenticator включает аутентификацию; без нее эндпоинты заказов открыты, а администрирование выключено
+func (s *OrderHTTPServer) SetAuthenticator(authenticator interfaces.Authenticator) {
+	s.authenticator = authenticator
+}
+
+// SetRouteRoles переопределяет роли для маршрутов заказов; ключ — шаблон маршрута, например "GET /orders/{uid}"
+func (s *OrderHTTPServer) SetRouteRoles(routeRoles map[string][]models.Role) {
+	s.routeRoles = routeRoles
+}
+
+// SetPIIPolicy задает роли, которым персональные данные заказов отдаются без маскирования
+func (s *OrderHTTPServer) SetPIIPolicy(policy models.PIIPolicy) {
+	s.piiPolicy = policy
+}
+
+// presentOrder маскирует персональные данные заказа по роли вызывающей стороны
+func (s *OrderHTTPServer) presentOrder(r *http.Request, order models.Order) models.Order {
+	return s.piiPolicy.Apply(auth.PrincipalFromContext(r.Context()), order)
+}
+
+// credentialsFromRequest извлекает ключ из X-API-Key и токен из Authorization: Bearer
+func credentialsFromRequest(r *http.Request) models.Credentials {
+	credentials := models.Credentials{APIKey: r.Header.Get("X-API-Key")}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		credentials.BearerToken = strings.TrimSpace(token)
+	}
+	return credentials
+}
+
+// authenticateMiddleware определяет вызывающую сторону; права проверяются на уровне маршрута
+func (s *OrderHTTPServer) authenticateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credentials := credentialsFromRequest(r)
+		if s.authenticator == nil || credentials.Empty() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		principal, err := s.authenticator.Authenticate(ctx, credentials)
+		if err != nil {
+			ctx = context.WithValue(ctx, authErrorKey{}, err)
+		} else {
+			ctx = auth.ContextWithPrincipal(ctx, principal)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Долгоживущие маршруты не занимают слоты одновременной обработки
+var longLivedRoutes = map[string]bool{"GET /api/v1/orders/stream": true}
+
+// handleProtected регистрирует маршрут заказов с ролями по умолчанию или из SetRouteRoles.
+// Частота запросов ограничивается до проверки ролей, чтобы перебор ключей тоже упирался в лимит.
+func (s *OrderHTTPServer) handleProtected(mux *http.ServeMux, pattern string, handler http.Handler) {
+	roles := readerRoles
+	if override, ok := s.routeRoles[pattern]; ok {
+		roles = override
+	}
+
+	handler = s.requireRoles(roles, handler)
+	if !longLivedRoutes[pattern] {
+		handler = s.shedLoad(handler)
+	}
+	mux.Handle(pattern, s.rateLimit(pattern, handler))
+}
+
+// requireRoles пропускает запросы вызывающих сторон хотя бы с одной из ролей
+func (s *OrderHTTPServer) requireRoles(roles []models.Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.authenticator == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal := auth.PrincipalFromContext(r.Context())
+		if principal == nil {
+			detail := "Требуется аутентификация: заголовок X-API-Key или Authorization: Bearer"
+			if err, _ := r.Context().Value(authErrorKey{}).(error); errors.Is(err, models.ErrInvalidCredentials) {
+				detail = "Неверный ключ или токен"
+			}
+			slog.WarnContext(r.Context(), "Unauthenticated request", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="order-service"`)
+			writeProblem(w, r, http.StatusUnauthorized, codeUnauthorized, detail)
+			return
+		}
+
+		if !principal.HasAnyRole(roles...) {
+			slog.WarnContext(r.Context(), "Forbidden request", "path", r.URL.Path, "principal", principal.String(), "remote_addr", r.RemoteAddr)
+			writeProblem(w, r, http.StatusForbidden, codeForbidden, "Недостаточно прав для этого ресурса")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// principalName возвращает идентификатор вызывающей стороны для журналов аудита
+func principalName(r *http.Request) string {
+	return auth.PrincipalFromContext(r.Context()).String()
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"order-service/internal/domain/models"
+)
+
+// Ограничение тела пакетного запроса: с запасом на MaxBatchOrders идентификаторов по 255 символов
+const maxBatchBodyBytes = 256 << 10
+
+type batchGetRequest struct {
+	OrderUIDs []string `json:"order_uids"`
+}
+
+type batchGetResponse struct {
+	Orders           []models.Order `json:"orders"`
+	MissingOrderUIDs []string       `json:"missing_order_uids"`
+}
+
+// Обработчик пакетного получения заказов: кеш, затем один запрос к БД на все промахи
+func (s *OrderHTTPServer) batchGetOrdersHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeProblem(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Метод не поддерживается")
+			return
+		}
+		if !s.features.load().BatchGet {
+			writeProblem(w, r, http.StatusServiceUnavailable, codeFeatureDisabled, "Пакетное чтение заказов выключено")
+			return
+		}
+
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			writeProblem(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, "Тело запроса должно быть в формате application/json")
+			return
+		}
+
+		if negotiate(r, "application/json") == "" {
+			writeProblem(w, r, http.StatusNotAcceptable, codeNotAcceptable, "Ресурс доступен только в формате application/json")
+			return
+		}
+
+		var req batchGetRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeProblem(w, r, http.StatusRequestEntityTooLarge, codeRequestTooLarge,
+					fmt.Sprintf("Тело запроса больше %d байт", maxBatchBodyBytes))
+				return
+			}
+			writeProblem(w, r, http.StatusBadRequest, codeInvalidRequestBody, "Ожидается объект {\"order_uids\": [...]}")
+			return
+		}
+
+		orders, missing, err := s.orders.GetMany(r.Context(), req.OrderUIDs)
+		if errors.Is(err, models.ErrBatchTooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeBatchTooLarge,
+				fmt.Sprintf("Не больше %d заказов в одном запросе", models.MaxBatchOrders))
+			return
+		} else if err != nil {
+			slog.ErrorContext(r.Context(), "Database query error", "error", err, "count", len(req.OrderUIDs))
+			writeProblem(w, r, http.StatusInternalServerError, codeInternal, "Ошибка при запросе к БД")
+			return
+		}
+
+		for i := range orders {
+			orders[i] = s.presentOrder(r, orders[i])
+		}
+		if missing == nil {
+			missing = []string{}
+		}
+		writeJSON(w, http.StatusOK, batchGetResponse{Orders: orders, MissingOrderUIDs: missing})
+	}
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Ответы меньше этого размера не сжимаются: выигрыш меньше накладных расходов
+const minCompressSize = 512
+
+// Сжимаемые типы содержимого; поток событий не сжимается, чтобы не буферизовать его
+var compressibleTypes = map[string]bool{
+	"application/json":         true,
+	"application/problem+json": true,
+	"text/html":                true,
+	"text/plain":               true,
+}
+
+// compressMiddleware сжимает ответы в gzip или brotli по заголовку Accept-Encoding
+func (s *OrderHTTPServer) compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &compressResponseWriter{
+			ResponseWriter: w,
+			encoding:       negotiateEncoding(r.Header.Get("Accept-Encoding")),
+		}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding выбирает br или gzip с учетом q-значений; при равенстве предпочитается br
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		switch name {
+		case "br":
+			if q >= bestQ {
+				best, bestQ = "br", q
+			}
+		case "gzip", "x-gzip":
+			if q > bestQ {
+				best, bestQ = "gzip", q
+			}
+		}
+	}
+	return best
+}
+
+// compressResponseWriter копит начало ответа неизвестной длины, пока не станет ясно,
+// стоит ли его сжимать; ответы других типов проходят без изменений
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	statusCode  int
+	wroteHeader bool
+	buffering   bool
+	buf         []byte
+	writer      io.WriteCloser
+}
+
+func (w *compressResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.statusCode = statusCode
+
+	if !w.compressible(statusCode) {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	// Представление зависит от Accept-Encoding, даже если клиент не просил сжатия
+	w.Header().Add("Vary", "Accept-Encoding")
+	if w.encoding == "" {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	if w.Header().Get("Content-Length") != "" {
+		w.startCompression()
+		return
+	}
+	w.buffering = true
+}
+
+// compressible проверяет статус и тип ответа; короткие ответы с известной длиной не сжимаются
+func (w *compressResponseWriter) compressible(statusCode int) bool {
+	header := w.Header()
+	if statusCode < http.StatusOK || statusCode == http.StatusNoContent ||
+		statusCode == http.StatusNotModified || statusCode == http.StatusPartialContent {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < minCompressSize {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && compressibleTypes[mediaType]
+}
+
+func (w *compressResponseWriter) startCompression() {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	// Сжатое представление отличается побайтно, поэтому сильный ETag становится слабым
+	if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		header.Set("ETag", "W/"+etag)
+	}
+
+	if w.encoding == "br" {
+		w.writer = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+	} else {
+		w.writer, _ = gzip.NewWriterLevel(w.ResponseWriter, gzip.DefaultCompression)
+	}
+	w.ResponseWriter.WriteHeader(w.statusCode)
+}
+
+// flushBuffer завершает буферизацию: со сжатием, если данных набралось достаточно
+func (w *compressResponseWriter) flushBuffer(compress bool) error {
+	w.buffering = false
+	buf := w.buf
+	w.buf = nil
+
+	if compress {
+		w.startCompression()
+		_, err := w.writer.Write(buf)
+		return err
+	}
+
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) >= minCompressSize {
+			if err := w.flushBuffer(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+
+	if w.writer != nil {
+		return w.writer.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Close отправляет накопленный короткий ответ или дописывает хвост сжатого потока
+func (w *compressResponseWriter) Close() error {
+	if w.buffering {
+		return w.flushBuffer(false)
+	}
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	return nil
+}
+
+func (w *compressResponseWriter) Flush() {
+	if w.buffering {
+		w.flushBuffer(true)
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap дает http.ResponseController доступ к дедлайнам исходного ResponseWriter
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack нужен для перехода на WebSocket
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
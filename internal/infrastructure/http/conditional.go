@@ -0,0 +1,40 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SetCacheMaxAge задает max-age в Cache-Control для представлений заказа
+func (s *OrderHTTPServer) SetCacheMaxAge(maxAge time.Duration) {
+	s.cacheMaxAge = maxAge
+}
+
+// etagFor возвращает сильный ETag — хеш содержимого представления
+func etagFor(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// serveRepresentation отдает готовое представление с ETag и Cache-Control.
+// If-None-Match, HEAD и ответ 304 обрабатывает http.ServeContent. Last-Modified не отдается:
+// у заказа нет надежного времени изменения, а время записи в кеш меняется при каждой перезагрузке.
+func (s *OrderHTTPServer) serveRepresentation(w http.ResponseWriter, r *http.Request, body []byte, contentType string) {
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("ETag", etagFor(body))
+
+	// Данные заказа персональные, поэтому ответ кешируется только на стороне клиента
+	if s.cacheMaxAge > 0 {
+		header.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(s.cacheMaxAge.Seconds())))
+	} else {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+
+	// Нулевое время отключает Last-Modified и проверку If-Modified-Since
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
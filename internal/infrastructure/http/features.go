@@ -0,0 +1,36 @@
+package http
+
+import "sync/atomic"
+
+// Features — функции сервера, которые можно включать и выключать без перезапуска
+type Features struct {
+	// OrderStream включает поток событий /api/v1/orders/stream
+	OrderStream bool
+	// BatchGet включает пакетное чтение /api/v1/orders:batchGet
+	BatchGet bool
+}
+
+// DefaultFeatures возвращает набор с включенными функциями
+func DefaultFeatures() Features {
+	return Features{OrderStream: true, BatchGet: true}
+}
+
+// featureSet хранит текущий набор функций; читается на каждом запросе
+type featureSet struct {
+	current atomic.Pointer[Features]
+}
+
+func newFeatureSet(features Features) *featureSet {
+	set := &featureSet{}
+	set.current.Store(&features)
+	return set
+}
+
+func (f *featureSet) load() Features {
+	return *f.current.Load()
+}
+
+// SetFeatures включает и выключает функции; безопасен во время работы
+func (s *OrderHTTPServer) SetFeatures(features Features) {
+	s.features.current.Store(&features)
+}
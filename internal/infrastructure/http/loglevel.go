@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"order-service/internal/logger"
+)
+
+// logLevelsBody — уровни логирования в запросе и ответе /admin/log/level
+type logLevelsBody struct {
+	Level      string            `json:"level,omitempty"`
+	Components map[string]string `json:"components"`
+}
+
+func currentLogLevels() logLevelsBody {
+	body := logLevelsBody{
+		Level:      strings.ToLower(logger.Level().String()),
+		Components: make(map[string]string),
+	}
+	for component, level := range logger.ComponentLevels() {
+		body.Components[component] = strings.ToLower(level.String())
+	}
+	return body
+}
+
+// logLevelHandler возвращает общий уровень логирования и уровни компонентов
+func (s *OrderHTTPServer) logLevelHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, currentLogLevels())
+	}
+}
+
+// setLogLevelHandler меняет уровни до следующего перезапуска или перезагрузки log.* из конфигурации.
+// Отсутствующее поле components оставляет уровни компонентов прежними, пустой объект — сбрасывает их.
+func (s *OrderHTTPServer) setLogLevelHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req logLevelsBody
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, codeInvalidRequestBody, `Ожидается объект {"level": "debug", "components": {"kafka": "debug"}}`)
+			return
+		}
+
+		var level slog.Level
+		if req.Level != "" {
+			parsed, err := logger.ParseLevel(req.Level)
+			if err != nil {
+				writeProblem(w, r, http.StatusBadRequest, codeInvalidRequestBody, err.Error())
+				return
+			}
+			level = parsed
+		}
+		components := make(map[string]slog.Level, len(req.Components))
+		for component, value := range req.Components {
+			parsed, err := logger.ParseLevel(value)
+			if err != nil {
+				writeProblem(w, r, http.StatusBadRequest, codeInvalidRequestBody, component+": "+err.Error())
+				return
+			}
+			components[component] = parsed
+		}
+
+		if req.Level != "" {
+			logger.SetLevel(level)
+		}
+		if req.Components != nil {
+			logger.SetComponentLevels(components)
+		}
+
+		current := currentLogLevels()
+		slog.InfoContext(r.Context(), "Admin action", "action", "log_level", "level", current.Level, "components", current.Components, "principal", principalName(r), "remote_addr", r.RemoteAddr)
+		writeJSON(w, http.StatusOK, current)
+	}
+}
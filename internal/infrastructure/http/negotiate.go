@@ -0,0 +1,91 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate выбирает из offers тип, наиболее предпочтительный для клиента по заголовку Accept.
+// Без заголовка Accept выбирается первый из offers; пустая строка — ни один тип не подходит.
+func negotiate(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		// При равном качестве предпочитаем тип, который сервер указал раньше
+		if q := qualityOf(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// qualityOf возвращает q самого конкретного диапазона, которому соответствует offer
+func qualityOf(ranges []acceptRange, offer string) float64 {
+	for _, ar := range ranges {
+		if matchMediaRange(ar.mediaType, offer) {
+			return ar.q
+		}
+	}
+	return 0
+}
+
+// parseAccept разбирает заголовок Accept; диапазоны упорядочены от более конкретных к общим
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(qs, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return specificityOf(ranges[i].mediaType) > specificityOf(ranges[j].mediaType)
+	})
+
+	return ranges
+}
+
+func specificityOf(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func matchMediaRange(mediaRange, offer string) bool {
+	switch {
+	case mediaRange == "*/*":
+		return true
+	case strings.HasSuffix(mediaRange, "/*"):
+		return strings.HasPrefix(offer, strings.TrimSuffix(mediaRange, "*"))
+	default:
+		return mediaRange == offer
+	}
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// Машиночитаемые коды ошибок API
+const (
+	codeInvalidOrderUID      = "invalid_order_uid"
+	codeOrderNotFound        = "order_not_found"
+	codeNotAcceptable        = "not_acceptable"
+	codeMethodNotAllowed     = "method_not_allowed"
+	codeNotFound             = "not_found"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+	codeInternal             = "internal_error"
+	codeCacheKeyNotFound     = "cache_key_not_found"
+	codePrefixRequired       = "prefix_required"
+	codeWarmupInProgress     = "warmup_in_progress"
+	codeWarmupNotConfigured  = "warmup_not_configured"
+	codeStreamDisabled       = "stream_disabled"
+	codeFeatureDisabled      = "feature_disabled"
+	codeInvalidLastEventID   = "invalid_last_event_id"
+	codeInvalidRequestBody   = "invalid_request_body"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codeRequestTooLarge      = "request_too_large"
+	codeBatchTooLarge        = "batch_too_large"
+	codeRateLimited          = "rate_limited"
+	codeOverloaded           = "overloaded"
+	problemContentType       = "application/problem+json"
+	problemTypeBase          = "https://order-service.local/problems/"
+)
+
+// Problem — тело ошибки в формате RFC 7807 с расширением code
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// writeProblem отправляет ошибку в формате application/problem+json
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	problem := Problem{
+		Type:     problemTypeBase + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.RequestURI(),
+		Code:     code,
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode problem response", "error", err)
+	}
+}
@@ -0,0 +1,343 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"order-service/internal/infrastructure/auth"
+)
+
+const (
+	// Неиспользуемые корзины удаляются не чаще этого интервала
+	bucketSweepInterval = time.Minute
+	// Ограничения размера запроса для всех маршрутов; у пакетного получения свой, меньший предел
+	maxRequestBodyBytes = 1 << 20
+	maxHeaderBytes      = 64 << 10
+)
+
+// RateLimit — скорость пополнения (запросов в секунду) и емкость корзины; Rate <= 0 отключает ограничение
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig — ограничения частоты запросов к маршрутам заказов
+type RateLimitConfig struct {
+	// PerKey действует для аутентифицированных запросов, PerIP — для анонимных
+	PerKey RateLimit
+	PerIP  RateLimit
+	// Routes переопределяет оба ограничения для шаблона маршрута, например "GET /order"
+	Routes map[string]RateLimit
+	// TrustedProxies — сети прокси, которым можно верить в X-Forwarded-For
+	TrustedProxies []netip.Prefix
+	// MaxConcurrent — предел одновременно обрабатываемых запросов; 0 — без ограничения
+	MaxConcurrent int
+}
+
+// ParseRateLimit разбирает ограничение вида "rate:burst", например "10:20" или "0.5:1"
+func ParseRateLimit(value string) (RateLimit, error) {
+	rateStr, burstStr, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: expected rate:burst", value)
+	}
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate < 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate in %q", value)
+	}
+	burst, err := strconv.Atoi(burstStr)
+	if err != nil || burst < 1 {
+		return RateLimit{}, fmt.Errorf("invalid burst in %q", value)
+	}
+	return RateLimit{Rate: rate, Burst: burst}, nil
+}
+
+// ParseRouteRateLimits разбирает ограничения маршрутов вида "GET /order=5:10;..."
+func ParseRouteRateLimits(spec string) (map[string]RateLimit, error) {
+	routes := make(map[string]RateLimit)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid route rate limit %q: expected pattern=rate:burst", entry)
+		}
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.TrimSpace(pattern)] = limit
+	}
+	return routes, nil
+}
+
+// ParseTrustedProxies разбирает список сетей и адресов через запятую
+func ParseTrustedProxies(spec string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(spec, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter хранит корзины маркеров по ключу "маршрут|клиент"
+type rateLimiter struct {
+	mu        sync.Mutex
+	config    RateLimitConfig
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+
+	// Семафор одновременных запросов; nil — без ограничения
+	inflight chan struct{}
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{buckets: make(map[string]*tokenBucket), now: time.Now}
+	l.setConfig(config)
+	return l
+}
+
+// setConfig меняет ограничения на лету. Корзины сохраняются: при неизменных ограничениях
+// клиенты не получают полную корзину, при измененных — корзина приводится к новой емкости.
+func (l *rateLimiter) setConfig(config RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Запросы, занявшие слот в старом семафоре, освободят его сами
+	if config.MaxConcurrent != cap(l.inflight) {
+		l.inflight = nil
+		if config.MaxConcurrent > 0 {
+			l.inflight = make(chan struct{}, config.MaxConcurrent)
+		}
+	}
+
+	previous := l.config
+	l.config = config
+
+	now := l.now()
+	for key, bucket := range l.buckets {
+		pattern, client, _ := strings.Cut(key, "|")
+		authenticated := strings.HasPrefix(client, "key:")
+		before, after := previous.limitFor(pattern, authenticated), config.limitFor(pattern, authenticated)
+		if before == after {
+			continue
+		}
+		if after.Rate <= 0 {
+			delete(l.buckets, key)
+			continue
+		}
+
+		// Досчитываем маркеры по прежней скорости, дальше корзина пополняется по новой
+		if before.Rate > 0 {
+			bucket.tokens = math.Min(float64(before.Burst), bucket.tokens+now.Sub(bucket.last).Seconds()*before.Rate)
+		}
+		bucket.tokens = math.Min(float64(after.Burst), bucket.tokens)
+		bucket.last = now
+	}
+}
+
+// limitFor возвращает ограничение для маршрута и вида клиента
+func (c RateLimitConfig) limitFor(pattern string, authenticated bool) RateLimit {
+	if limit, ok := c.Routes[pattern]; ok {
+		return limit
+	}
+	if authenticated {
+		return c.PerKey
+	}
+	return c.PerIP
+}
+
+// allow забирает маркер из корзины клиента; при отказе возвращает время до появления маркера
+func (l *rateLimiter) allow(pattern, client string, authenticated bool) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.config.limitFor(pattern, authenticated)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	key := pattern + "|" + client
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+now.Sub(bucket.last).Seconds()*limit.Rate)
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep удаляет корзины, которые успели наполниться: они неотличимы от новых
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		pattern, client, _ := strings.Cut(key, "|")
+		limit := l.config.limitFor(pattern, strings.HasPrefix(client, "key:"))
+		if limit.Rate <= 0 || now.Sub(bucket.last).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// acquire занимает слот одновременной обработки; false — сервер перегружен
+func (l *rateLimiter) acquire() (release func(), ok bool) {
+	l.mu.Lock()
+	inflight := l.inflight
+	l.mu.Unlock()
+
+	if inflight == nil {
+		return func() {}, true
+	}
+	select {
+	case inflight <- struct{}{}:
+		return func() { <-inflight }, true
+	default:
+		return nil, false
+	}
+}
+
+// clientIP возвращает адрес клиента; X-Forwarded-For учитывается, только если запрос пришел от доверенного прокси
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	l.mu.Lock()
+	trusted := l.config.TrustedProxies
+	l.mu.Unlock()
+
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+	if !isTrusted(remote) {
+		return remote.Unmap().String()
+	}
+
+	// Идем справа налево: первый недоверенный адрес и есть клиент
+	client := remote
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr
+		if !isTrusted(addr) {
+			break
+		}
+	}
+	return client.Unmap().String()
+}
+
+// SetRateLimits включает или меняет ограничения частоты и одновременности запросов
+func (s *OrderHTTPServer) SetRateLimits(config RateLimitConfig) {
+	s.limiter.setConfig(config)
+}
+
+// rateLimit ограничивает частоту запросов к маршруту по ключу вызывающей стороны или по IP
+func (s *OrderHTTPServer) rateLimit(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, authenticated := "ip:"+s.limiter.clientIP(r), false
+		if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+			client, authenticated = "key:"+principal.String(), true
+		}
+
+		if ok, wait := s.limiter.allow(pattern, client, authenticated); !ok {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			slog.WarnContext(r.Context(), "Rate limit exceeded", "route", pattern, "client", client, "retry_after", retryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeProblem(w, r, http.StatusTooManyRequests, codeRateLimited, "Слишком много запросов, повторите позже")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// shedLoad отклоняет запросы сверх предела одновременной обработки, не доводя их до БД
+func (s *OrderHTTPServer) shedLoad(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, ok := s.limiter.acquire()
+		if !ok {
+			slog.WarnContext(r.Context(), "Server overloaded, shedding request", "path", r.URL.Path)
+			w.Header().Set("Retry-After", "1")
+			writeProblem(w, r, http.StatusServiceUnavailable, codeOverloaded, "Сервер перегружен, повторите позже")
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitRequestBody ограничивает размер тела любого запроса
+func limitRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxRequestBodyBytes {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, codeRequestTooLarge,
+				fmt.Sprintf("Тело запроса больше %d байт", maxRequestBodyBytes))
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"order-service/internal/logger"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// requestIDMiddleware берет идентификатор из X-Request-ID или создает новый,
+// кладет его в контекст для журналов и возвращает клиенту в ответе
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := logger.RequestIDOrNew(r.Header.Get(requestIDHeader))
+
+		w.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), requestID)))
+	})
+}
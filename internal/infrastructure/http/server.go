@@ -1,13 +1,15 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"context"
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
+	"net"
 	"net/http"
 	"path/filepath"
 	"time"
@@ -18,62 +20,181 @@ import (
 
 type OrderHTTPServer struct {
 	server    *http.Server
-	repo      interfaces.OrderRepository
+	orders    interfaces.OrderService
 	cache     interfaces.CacheRepository
 	port      int
 	isRunning bool
+	// failures получает ошибку, если сервер перестал принимать соединения после запуска
+	failures chan error
+
+	// Аутентификация и роли маршрутов заказов
+	authenticator interfaces.Authenticator
+	routeRoles    map[string][]models.Role
+
+	// Маскирование персональных данных для ролей без доступа к ним
+	piiPolicy models.PIIPolicy
+
+	// Ограничение частоты и одновременности запросов к маршрутам заказов
+	limiter *rateLimiter
+
+	// Администрирование кеша
+	adminEnabled bool
+	warmer       interfaces.CacheWarmer
+	publisher    interfaces.InvalidationPublisher
+
+	// Шина событий для потока /api/v1/orders/stream
+	events interfaces.OrderEventBus
+
+	// max-age в Cache-Control для представлений заказа
+	cacheMaxAge time.Duration
+
+	// Таймауты http.Server
+	timeouts Timeouts
+
+	// Функции, включаемые без перезапуска
+	features *featureSet
+
+	// Перезагрузка конфигурации через /admin/config/reload
+	reloader interfaces.ConfigReloader
+
+	// Контекст фоновых задач сервера, отменяется при остановке
+	baseCtx    context.Context
+	cancelBase context.CancelFunc
 }
 
-func NewOrderHTTPServer(port int, repo interfaces.OrderRepository, cache interfaces.CacheRepository) *OrderHTTPServer {
+// Timeouts — таймауты чтения, записи и простоя соединений HTTP-сервера
+type Timeouts struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// DefaultTimeouts возвращает таймауты по умолчанию
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Read:       10 * time.Second,
+		ReadHeader: 5 * time.Second,
+		Write:      10 * time.Second,
+		Idle:       60 * time.Second,
+	}
+}
+
+func NewOrderHTTPServer(port int, orders interfaces.OrderService, cache interfaces.CacheRepository) *OrderHTTPServer {
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+
 	return &OrderHTTPServer{
-		port:  port,
-		repo:  repo,
-		cache: cache,
+		port:       port,
+		orders:     orders,
+		cache:      cache,
+		piiPolicy:  models.DefaultPIIPolicy(),
+		limiter:    newRateLimiter(RateLimitConfig{}),
+		timeouts:   DefaultTimeouts(),
+		features:   newFeatureSet(DefaultFeatures()),
+		baseCtx:    baseCtx,
+		cancelBase: cancelBase,
+	}
+}
+
+// SetTimeouts задает таймауты сервера; действует при следующем Start
+func (s *OrderHTTPServer) SetTimeouts(timeouts Timeouts) {
+	s.timeouts = timeouts
+}
+
+// SetInvalidationPublisher включает рассылку инвалидаций при удалении данных из кеша через API
+func (s *OrderHTTPServer) SetInvalidationPublisher(publisher interfaces.InvalidationPublisher) {
+	s.publisher = publisher
+}
+
+// SetEventBus включает поток событий о заказах
+func (s *OrderHTTPServer) SetEventBus(events interfaces.OrderEventBus) {
+	s.events = events
+}
+
+// EnableAdmin включает эндпоинты администрирования кеша; они требуют роль admin
+func (s *OrderHTTPServer) EnableAdmin(warmer interfaces.CacheWarmer) {
+	s.adminEnabled = true
+	s.warmer = warmer
+}
+
+// Handler возвращает корневой обработчик со всеми маршрутами и middleware
+func (s *OrderHTTPServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Регистрируем обработчики; маршруты заказов требуют одну из ролей
+	s.handleProtected(mux, "GET /api/v1/orders/stream", s.orderStreamHandler())
+	s.handleProtected(mux, "/api/v1/orders/{uid}", s.getOrderHandler())
+	s.handleProtected(mux, "/api/v1/orders:batchGet", s.batchGetOrdersHandler())
+	mux.HandleFunc("GET /api/openapi.json", s.openAPISpecHandler())
+	mux.HandleFunc("GET /api/docs", s.openAPIDocsHandler())
+	mux.HandleFunc("/api/", s.notFoundHandler())
+	s.handleProtected(mux, "GET /orders/{uid}", s.orderPageHandler())
+	mux.Handle("GET /order", s.rateLimit("GET /order", s.legacyOrderHandler()))
+	mux.HandleFunc("/health", s.healthCheckHandler())
+
+	if s.authenticator == nil {
+		slog.Warn("Authentication disabled: order endpoints are publicly accessible")
 	}
+
+	switch {
+	case !s.adminEnabled:
+	case s.authenticator == nil:
+		slog.Warn("Admin endpoints disabled: authentication is not configured")
+	default:
+		s.registerAdminRoutes(mux)
+	}
+
+	// Middleware для аутентификации, логирования запросов, ограничения тела и сжатия ответов
+	return requestIDMiddleware(traceMiddleware(mux, s.authenticateMiddleware(s.loggingMiddleware(limitRequestBody(s.compressMiddleware(mux))))))
 }
 
+// Start занимает порт и начинает обслуживать запросы в фоне. Если порт занят, возвращает ошибку сразу;
+// отказ во время работы приходит в канал Failures.
 func (s *OrderHTTPServer) Start() error {
 	if s.isRunning {
 		return nil
 	}
 
 	addr := fmt.Sprintf(":%d", s.port)
-	mux := http.NewServeMux()
-
-	// Регистрируем обработчики
-	mux.HandleFunc("/order", s.getOrderHandler())
-	mux.HandleFunc("/health", s.healthCheckHandler())
-
-	// Middleware для логирования запросов
-	wrappedHandler := s.loggingMiddleware(mux)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
 
 	s.server = &http.Server{
-		Addr:         addr,
-		Handler:      wrappedHandler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadTimeout:       s.timeouts.Read,
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+		MaxHeaderBytes:    maxHeaderBytes,
 	}
 
 	s.isRunning = true
+	failures := make(chan error, 1)
+	s.failures = failures
 
+	server := s.server
 	go func() {
 		slog.Info("HTTP server started", "address", fmt.Sprintf("http://localhost%s", addr))
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			// Улучшенное логирование при ошибке запуска
-			slog.Error("HTTP server failed to start or encountered runtime error",
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("HTTP server encountered runtime error",
 				"error", err,
 				"address", addr,
 				"port", s.port)
-
-			// Можно добавить метрику или уведомление об отказе сервера
-			// Например, увеличить счетчик ошибок или отправить уведомление
+			failures <- fmt.Errorf("HTTP server stopped serving: %w", err)
 		}
 	}()
 
 	return nil
 }
 
+// Failures возвращает канал отказов текущего запуска сервера
+func (s *OrderHTTPServer) Failures() <-chan error {
+	return s.failures
+}
+
 // Middleware для логирования
 func (s *OrderHTTPServer) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -86,13 +207,14 @@ func (s *OrderHTTPServer) loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(ww, r)
 
 		// Логируем результат
-		slog.Info("HTTP request",
+		slog.InfoContext(r.Context(), "HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", ww.statusCode,
 			"duration", time.Since(start),
 			"remote_addr", r.RemoteAddr,
 			"user_agent", r.UserAgent(),
+			"principal", principalName(r),
 		)
 	})
 }
@@ -108,6 +230,20 @@ func (w *responseWriterWrapper) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap дает http.ResponseController доступ к Flush и дедлайнам исходного ResponseWriter
+func (w *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack нужен для перехода на WebSocket
+func (w *responseWriterWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.statusCode = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
 // Обработчик для проверки работоспособности
 func (s *OrderHTTPServer) healthCheckHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -120,103 +256,118 @@ func (s *OrderHTTPServer) healthCheckHandler() http.HandlerFunc {
 	}
 }
 
-// Обработчик для получения заказа
+// orderFromRequest проверяет идентификатор из пути и загружает заказ; при ошибке ответ уже отправлен
+func (s *OrderHTTPServer) orderFromRequest(w http.ResponseWriter, r *http.Request) (*models.Order, bool) {
+	orderUID := r.PathValue("uid")
+	slog.InfoContext(r.Context(), "Order lookup request", "orderUID", orderUID, "remote_addr", r.RemoteAddr)
+
+	order, err := s.orders.Get(r.Context(), orderUID)
+	if errors.Is(err, models.ErrInvalidOrderUID) {
+		slog.WarnContext(r.Context(), "Invalid order UID", "orderUID", orderUID, "remote_addr", r.RemoteAddr)
+		writeProblem(w, r, http.StatusBadRequest, codeInvalidOrderUID,
+			"Идентификатор заказа должен состоять из латиницы, цифр, '-' и '_' и быть не длиннее 255 символов")
+		return nil, false
+	} else if errors.Is(err, models.ErrOrderNotFound) {
+		slog.InfoContext(r.Context(), "Order not found", "orderUID", orderUID)
+		writeProblem(w, r, http.StatusNotFound, codeOrderNotFound, "Заказ не найден")
+		return nil, false
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "Database query error", "error", err, "orderUID", orderUID)
+		writeProblem(w, r, http.StatusInternalServerError, codeInternal, "Ошибка при запросе к БД")
+		return nil, false
+	}
+
+	return order, true
+}
+
+// Обработчик API для получения заказа в JSON
 func (s *OrderHTTPServer) getOrderHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Проверяем, что метод GET
-		if r.Method != http.MethodGet {
-			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeProblem(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Метод не поддерживается")
 			return
 		}
 
-		orderUID := r.URL.Query().Get("id")
-		if orderUID == "" {
-			slog.Warn("Missing id parameter", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
-			http.Error(w, "Параметр 'id' обязателен", http.StatusBadRequest)
+		if negotiate(r, "application/json") == "" {
+			writeProblem(w, r, http.StatusNotAcceptable, codeNotAcceptable,
+				"Ресурс доступен только в формате application/json; HTML-представление — /orders/{uid}")
 			return
 		}
 
-		slog.Info("Order lookup request", "orderUID", orderUID, "remote_addr", r.RemoteAddr)
-
-		// Формат ответа (JSON или HTML)
-		format := r.URL.Query().Get("format")
-		if format == "" {
-			format = "html" // По умолчанию используем HTML
+		order, ok := s.orderFromRequest(w, r)
+		if !ok {
+			return
 		}
 
-		// Сначала проверяем кэш
-		var order *models.Order
-		cachedData, found := s.cache.Get(orderUID)
-
-		if found {
-			// Данные найдены в кэше
-			var cachedOrder models.Order
-			if err := json.Unmarshal(cachedData, &cachedOrder); err == nil {
-				slog.Info("Order found in cache", "orderUID", orderUID)
-				order = &cachedOrder
-			} else {
-				slog.Error("Failed to unmarshal cached order", "error", err)
-				// Продолжаем и попробуем получить из БД
-			}
+		body, err := json.Marshal(s.presentOrder(r, *order))
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to encode order to JSON", "error", err)
+			writeProblem(w, r, http.StatusInternalServerError, codeInternal, "Ошибка при формировании ответа")
+			return
 		}
 
-		// Если не нашли в кэше, ищем в БД
-		if order == nil {
-			var err error
-			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
-			defer cancel()
-
-			order, err = s.repo.GetOrder(ctx, orderUID)
-			if err == sql.ErrNoRows {
-				slog.Info("Order not found", "orderUID", orderUID)
-				http.Error(w, "Заказ не найден", http.StatusNotFound)
-				return
-			} else if err != nil {
-				slog.Error("Database query error", "error", err, "orderUID", orderUID)
-				http.Error(w, "Ошибка при запросе к БД", http.StatusInternalServerError)
-				return
-			}
-
-			// Сохраняем заказ в кэш
-			if order != nil {
-				orderJSON, err := json.Marshal(order)
-				if err == nil {
-					s.cache.Set(orderUID, orderJSON)
-				} else {
-					slog.Error("Failed to marshal order for caching", "error", err)
-				}
-			}
+		w.Header().Add("Vary", "Accept")
+		s.serveRepresentation(w, r, body, "application/json")
+	}
+}
+
+// Обработчик HTML-страницы заказа
+func (s *OrderHTTPServer) orderPageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		order, ok := s.orderFromRequest(w, r)
+		if !ok {
+			return
 		}
 
-		// В зависимости от формата, возвращаем HTML или JSON
-		if format == "json" {
-			w.Header().Set("Content-Type", "application/json")
-			if err := json.NewEncoder(w).Encode(order); err != nil {
-				slog.Error("Failed to encode order to JSON", "error", err)
-				http.Error(w, "Ошибка сервера при отображении данных", http.StatusInternalServerError)
-			}
+		s.renderOrderTemplate(w, r, order)
+	}
+}
+
+// legacyOrderHandler перенаправляет старые запросы /order?id= на новые маршруты
+func (s *OrderHTTPServer) legacyOrderHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderUID := r.URL.Query().Get("id")
+		if !models.ValidOrderUID(orderUID) {
+			slog.WarnContext(r.Context(), "Missing or invalid id parameter", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			writeProblem(w, r, http.StatusBadRequest, codeInvalidOrderUID, "Параметр 'id' обязателен")
 			return
 		}
 
-		// По умолчанию - HTML
-		s.renderOrderTemplate(w, order)
+		target := "/orders/" + orderUID
+		if r.URL.Query().Get("format") == "json" || negotiate(r, "text/html", "application/json") == "application/json" {
+			target = "/api/v1/orders/" + orderUID
+		}
+
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
 	}
 }
 
-func (s *OrderHTTPServer) renderOrderTemplate(w http.ResponseWriter, order *models.Order) {
+// notFoundHandler отвечает на запросы к неизвестным маршрутам API
+func (s *OrderHTTPServer) notFoundHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusNotFound, codeNotFound, "Маршрут не найден")
+	}
+}
+
+func (s *OrderHTTPServer) renderOrderTemplate(w http.ResponseWriter, r *http.Request, order *models.Order) {
 	tmplPath := filepath.Join("templates", "order.html")
 	tmpl, err := template.ParseFiles(tmplPath)
 	if err != nil {
-		slog.Error("Failed to parse template", "error", err)
-		http.Error(w, "Ошибка сервера при отображении шаблона", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Failed to parse template", "error", err)
+		writeProblem(w, r, http.StatusInternalServerError, codeInternal, "Ошибка сервера при отображении шаблона")
 		return
 	}
 
-	if err := tmpl.Execute(w, order); err != nil {
-		slog.Error("Failed to execute template", "error", err)
-		http.Error(w, "Ошибка сервера при отображении данных", http.StatusInternalServerError)
+	// Рендерим в буфер, чтобы при ошибке не отправить клиенту половину страницы
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s.presentOrder(r, *order)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to execute template", "error", err)
+		writeProblem(w, r, http.StatusInternalServerError, codeInternal, "Ошибка сервера при отображении данных")
 		return
 	}
+
+	s.serveRepresentation(w, r, buf.Bytes(), "text/html; charset=utf-8")
 }
 
 func (s *OrderHTTPServer) Shutdown(ctx context.Context) error {
@@ -226,19 +377,17 @@ func (s *OrderHTTPServer) Shutdown(ctx context.Context) error {
 
 	slog.Info("HTTP server shutting down")
 
-	// Создаем контекст с таймаутом для корректного завершения
-	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+	// Останавливаем фоновые задачи, запущенные через API (например, прогрев кеша)
+	s.cancelBase()
 
-	// Сначала пытаемся корректно завершить работу
-	err := s.server.Shutdown(shutdownCtx)
+	// Сначала пытаемся корректно завершить работу; время ограничено контекстом вызывающей стороны
+	err := s.server.Shutdown(ctx)
 
 	// Проверяем результат завершения
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			slog.Error("HTTP server shutdown timed out, some connections may be forcibly closed",
-				"error", err,
-				"timeout", "30s")
+				"error", err)
 		} else {
 			slog.Error("HTTP server shutdown error",
 				"error", err)
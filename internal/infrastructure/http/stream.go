@@ -0,0 +1,223 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"order-service/internal/domain/models"
+)
+
+const (
+	streamBuffer       = 256
+	streamWriteTimeout = 10 * time.Second
+	streamHeartbeat    = 15 * time.Second
+	streamRetry        = 3 * time.Second
+	wsPongWait         = 60 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// orderStreamHandler отдает события о заказах через WebSocket (при запросе Upgrade) или Server-Sent Events.
+// Медленный клиент отключается шиной и может переподключиться с Last-Event-ID.
+func (s *OrderHTTPServer) orderStreamHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.events == nil || !s.features.load().OrderStream {
+			writeProblem(w, r, http.StatusServiceUnavailable, codeStreamDisabled, "Поток событий о заказах не настроен или выключен")
+			return
+		}
+
+		lastEventID, err := parseLastEventID(r)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, codeInvalidLastEventID, "Last-Event-ID должен быть неотрицательным целым числом")
+			return
+		}
+		filter := streamFilter(r)
+
+		if websocket.IsWebSocketUpgrade(r) {
+			s.serveWebSocket(w, r, filter, lastEventID)
+			return
+		}
+
+		if negotiate(r, "text/event-stream") == "" {
+			writeProblem(w, r, http.StatusNotAcceptable, codeNotAcceptable,
+				"Поток доступен как text/event-stream или через WebSocket")
+			return
+		}
+		s.serveSSE(w, r, filter, lastEventID)
+	}
+}
+
+func (s *OrderHTTPServer) serveSSE(w http.ResponseWriter, r *http.Request, filter models.OrderEventFilter, lastEventID uint64) {
+	rc := http.NewResponseController(w)
+
+	events, unsubscribe := s.events.Subscribe(filter, lastEventID, streamBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Отключаем буферизацию в nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	slog.InfoContext(r.Context(), "Order stream started", "transport", "sse", "last_event_id", lastEventID, "remote_addr", r.RemoteAddr)
+	defer slog.InfoContext(r.Context(), "Order stream finished", "transport", "sse", "remote_addr", r.RemoteAddr)
+
+	// write ограничивает время каждой записи вместо общего WriteTimeout сервера
+	write := func(chunk string) bool {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprint(w, chunk); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !write(fmt.Sprintf("retry: %d\n\n", streamRetry.Milliseconds())) {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.baseCtx.Done():
+			return
+		case <-heartbeat.C:
+			if !write(": heartbeat\n\n") {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// Клиент не успевал читать; EventSource переподключится с Last-Event-ID
+				slog.WarnContext(r.Context(), "Order stream overflow, disconnecting client", "transport", "sse", "remote_addr", r.RemoteAddr)
+				write("event: overflow\ndata: {}\n\n")
+				return
+			}
+
+			event.Order = s.presentOrder(r, event.Order)
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Failed to marshal order event", "error", err)
+				continue
+			}
+			if !write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)) {
+				return
+			}
+		}
+	}
+}
+
+func (s *OrderHTTPServer) serveWebSocket(w http.ResponseWriter, r *http.Request, filter models.OrderEventFilter, lastEventID uint64) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader уже отправил ответ с ошибкой
+		slog.WarnContext(r.Context(), "WebSocket upgrade failed", "error", err, "remote_addr", r.RemoteAddr)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := s.events.Subscribe(filter, lastEventID, streamBuffer)
+	defer unsubscribe()
+
+	slog.InfoContext(r.Context(), "Order stream started", "transport", "websocket", "last_event_id", lastEventID, "remote_addr", r.RemoteAddr)
+	defer slog.InfoContext(r.Context(), "Order stream finished", "transport", "websocket", "remote_addr", r.RemoteAddr)
+
+	// Читаем входящие кадры, чтобы обрабатывать pong и закрытие соединения клиентом
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	closeWith := func(code int, text string) {
+		deadline := time.Now().Add(time.Second)
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), deadline)
+	}
+
+	ping := time.NewTicker(streamHeartbeat)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.baseCtx.Done():
+			closeWith(websocket.CloseGoingAway, "server shutting down")
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				slog.WarnContext(r.Context(), "Order stream overflow, disconnecting client", "transport", "websocket", "remote_addr", r.RemoteAddr)
+				closeWith(websocket.CloseTryAgainLater, "subscriber is too slow")
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			event.Order = s.presentOrder(r, event.Order)
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseLastEventID читает ID последнего полученного события из заголовка Last-Event-ID
+// или параметра last_event_id (браузерный WebSocket не позволяет задать заголовок)
+func parseLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+}
+
+// streamFilter собирает фильтр из параметров запроса; значения можно повторять или перечислять через запятую
+func streamFilter(r *http.Request) models.OrderEventFilter {
+	query := r.URL.Query()
+	return models.OrderEventFilter{
+		DeliveryServices: splitQueryValues(query["delivery_service"]),
+		Regions:          splitQueryValues(query["region"]),
+		Currencies:       splitQueryValues(query["currency"]),
+	}
+}
+
+func splitQueryValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
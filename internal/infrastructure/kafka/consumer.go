@@ -4,41 +4,86 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"time"
 
 	"order-service/internal/domain/models"
+	"order-service/internal/logger"
+	"order-service/internal/tracing"
 	"order-service/pkg/interfaces"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// OrderKafkaConsumer читает заказы из Kafka и передает их в OrderService
 type OrderKafkaConsumer struct {
 	brokers   []string
 	topic     string
 	groupID   string
-	repo      interfaces.OrderRepository
-	cache     interfaces.CacheRepository
+	orders    interfaces.OrderService
 	isRunning bool
-	reader    *kafka.Reader
+	reader    MessageReader
+	newReader func(kafka.ReaderConfig) MessageReader
+	timeouts  ConsumerTimeouts
+	readLoop
 }
 
-func NewOrderKafkaConsumer(brokers []string, topic, groupID string, repo interfaces.OrderRepository, cache interfaces.CacheRepository) *OrderKafkaConsumer {
+// MessageReader — чтение и подтверждение сообщений; по умолчанию *kafka.Reader
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// ConsumerTimeouts — таймауты ожидания сообщения и сохранения заказа
+type ConsumerTimeouts struct {
+	Fetch time.Duration
+	Save  time.Duration
+}
+
+// DefaultConsumerTimeouts возвращает таймауты по умолчанию
+func DefaultConsumerTimeouts() ConsumerTimeouts {
+	return ConsumerTimeouts{Fetch: 5 * time.Second, Save: 5 * time.Second}
+}
+
+func NewOrderKafkaConsumer(brokers []string, topic, groupID string, orders interfaces.OrderService) *OrderKafkaConsumer {
 	return &OrderKafkaConsumer{
-		brokers: brokers,
-		topic:   topic,
-		groupID: groupID,
-		repo:    repo,
-		cache:   cache,
+		brokers:  brokers,
+		topic:    topic,
+		groupID:  groupID,
+		orders:   orders,
+		timeouts: DefaultConsumerTimeouts(),
+		newReader: func(config kafka.ReaderConfig) MessageReader {
+			return kafka.NewReader(config)
+		},
 	}
 }
 
+// SetReaderFactory задает создание reader при каждом запуске потребителя
+func (c *OrderKafkaConsumer) SetReaderFactory(newReader func(kafka.ReaderConfig) MessageReader) {
+	c.newReader = newReader
+}
+
+// SetTimeouts задает таймауты обработки сообщений
+func (c *OrderKafkaConsumer) SetTimeouts(timeouts ConsumerTimeouts) {
+	c.timeouts = timeouts
+}
+
 func (c *OrderKafkaConsumer) Start(ctx context.Context) error {
 	if c.isRunning {
-		return nil
+		if !c.readLoop.stopping.Load() {
+			return nil
+		}
+		// Shutdown не дождался горутины: второй reader читал бы параллельно с ней
+		if !c.readLoop.finished() {
+			return errReadLoopStopping
+		}
 	}
 
-	c.reader = kafka.NewReader(kafka.ReaderConfig{
+	c.reader = c.newReader(kafka.ReaderConfig{
 		Brokers:         c.brokers,
 		Topic:           c.topic,
 		GroupID:         c.groupID,
@@ -55,89 +100,102 @@ func (c *OrderKafkaConsumer) Start(ctx context.Context) error {
 
 	c.isRunning = true
 
-	go c.processMessages(ctx)
+	reader := c.reader
+	c.readLoop.start("order consumer", func() error { return c.processMessages(ctx, reader) })
 
 	return nil
 }
 
-func (c *OrderKafkaConsumer) processMessages(ctx context.Context) {
+// processMessages читает сообщения до отмены ctx или закрытия reader; ошибка означает,
+// что чтение прекратилось само
+func (c *OrderKafkaConsumer) processMessages(ctx context.Context, reader MessageReader) error {
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("Kafka consumer context canceled")
-			return
+			return nil
 		default:
 		}
 
-		msgCtx, msgCancel := context.WithTimeout(ctx, 5*time.Second)
-		msg, err := c.reader.FetchMessage(msgCtx)
+		msgCtx, msgCancel := context.WithTimeout(ctx, c.timeouts.Fetch)
+		msg, err := reader.FetchMessage(msgCtx)
 		msgCancel()
 
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				continue
 			}
+			// Reader закрыт: при остановке это штатно, иначе сообщения больше не читаются
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("kafka reader closed: %w", err)
+			}
 
 			slog.Error("Error reading message from Kafka", "error", err)
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
 
-		// Обработка сообщения
-		slog.Info("Received message", "partition", msg.Partition, "offset", msg.Offset)
-
-		var order models.Order
-		if err := json.Unmarshal(msg.Value, &order); err != nil {
-			slog.Error("Failed to parse message", "error", err, "raw_message", string(msg.Value))
-			// Подтверждаем даже некорректные сообщения, чтобы не застревать
-			if err := c.reader.CommitMessages(ctx, msg); err != nil {
-				slog.Error("Failed to commit message", "error", err)
-			}
-			continue
-		}
-
-		// Проверяем, обрабатывали ли мы уже этот заказ
-		if c.cache.Has(order.OrderUID) {
-			slog.Info("Order already processed, skipping", "orderUID", order.OrderUID)
-			if err := c.reader.CommitMessages(ctx, msg); err != nil {
-				slog.Error("Failed to commit message", "error", err)
-			}
-			continue
-		}
-
-		// Сохраняем заказ в базу данных
-		saveCtx, saveCancel := context.WithTimeout(ctx, 5*time.Second)
-		err = c.repo.SaveOrder(saveCtx, order)
-		saveCancel()
-
-		if err != nil {
-			slog.Error("Failed to save order", "error", err, "orderUID", order.OrderUID)
-			// Не подтверждаем сообщение, чтобы обработать его позже
-			continue
-		}
-
-		// Сохраняем данные заказа в кеш
-		cachedData, err := json.Marshal(order)
-		if err != nil {
-			slog.Error("Failed to marshal order for cache", "error", err)
-		} else {
-			c.cache.Set(order.OrderUID, cachedData)
-
-			// Можно также сохранить сериализованные данные в БД для быстрого восстановления кеша
-			if err := c.repo.CacheOrderData(order.OrderUID, cachedData); err != nil {
-				slog.Error("Failed to cache order data in DB", "error", err)
-			}
-		}
+		c.handleMessage(ctx, reader, msg)
+	}
+}
 
-		// Подтверждаем обработку сообщения
-		if err := c.reader.CommitMessages(ctx, msg); err != nil {
-			slog.Error("Failed to commit message", "error", err)
-		} else {
-			slog.Info("Message processed and committed", "orderUID", order.OrderUID)
-		}
+// handleMessage обрабатывает одно сообщение; раздел, смещение и UID заказа
+// попадают в контекст и затем во все записи журнала об этом сообщении
+func (c *OrderKafkaConsumer) handleMessage(ctx context.Context, reader MessageReader, msg kafka.Message) {
+	// Трассировка продолжает span генератора, если он передал traceparent
+	ctx, span := startConsumerSpan(ctx, &msg)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	ctx = logger.WithAttrs(ctx, slog.Int("partition", msg.Partition), slog.Int64("offset", msg.Offset))
+	slog.InfoContext(ctx, "Received message")
+
+	var order models.Order
+	if err = json.Unmarshal(msg.Value, &order); err != nil {
+		// Персональные данные в raw_message маскирует logger.RedactingHandler
+		slog.ErrorContext(ctx, "Failed to parse message", "error", err, "raw_message", string(msg.Value))
+		// Подтверждаем даже некорректные сообщения, чтобы не застревать
+		c.commit(ctx, reader, msg)
+		return
+	}
+	ctx = logger.WithAttrs(ctx, slog.String("orderUID", order.OrderUID))
+	span.SetAttributes(attribute.String("order.uid", order.OrderUID))
+
+	// Сохраняем заказ; кеш, идемпотентность и события — забота OrderService
+	saveCtx, saveCancel := context.WithTimeout(ctx, c.timeouts.Save)
+	result, err := c.orders.Ingest(saveCtx, order)
+	saveCancel()
+
+	switch {
+	case errors.Is(err, models.ErrInvalidOrder):
+		slog.ErrorContext(ctx, "Invalid order, skipping", "error", err)
+		// Некорректный заказ не станет корректным при повторной обработке
+		c.commit(ctx, reader, msg)
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "Failed to save order", "error", err)
+		// Не подтверждаем сообщение, чтобы обработать его позже
+		return
+	case result == models.OrderIngestDuplicate:
+		slog.InfoContext(ctx, "Order already processed, skipping")
+		c.commit(ctx, reader, msg)
+		return
+	}
 
+	// Подтверждаем обработку сообщения
+	if c.commit(ctx, reader, msg) {
+		slog.InfoContext(ctx, "Message processed and committed", "result", result)
 	}
+}
 
+// commit подтверждает сообщение через reader, из которого оно прочитано: после остановки
+// c.reader может относиться уже к другому запуску
+func (c *OrderKafkaConsumer) commit(ctx context.Context, reader MessageReader, msg kafka.Message) bool {
+	if err := reader.CommitMessages(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "Failed to commit message", "error", err)
+		return false
+	}
+	return true
 }
 
 func (c *OrderKafkaConsumer) Shutdown(ctx context.Context) error {
@@ -147,11 +205,21 @@ func (c *OrderKafkaConsumer) Shutdown(ctx context.Context) error {
 
 	slog.Info("Kafka consumer shutting down")
 
-	if err := c.reader.Close(); err != nil {
-		slog.Error("Kafka reader close failed", "error", err)
-		return err
+	c.readLoop.stop()
+	closeErr := c.reader.Close()
+	if closeErr != nil {
+		slog.Error("Kafka reader close failed", "error", closeErr)
+		closeErr = fmt.Errorf("failed to close kafka reader: %w", closeErr)
+	}
+	// Дожидаемся обработки текущего сообщения, чтобы перезапуск не читал параллельно
+	waitErr := c.readLoop.wait(ctx)
+	if waitErr != nil {
+		// Горутина еще работает со своим reader: запуск не завершен, и Start откажет,
+		// пока она не остановится
+		return errors.Join(closeErr, waitErr)
 	}
 
 	c.isRunning = false
-	return nil
+	c.reader = nil
+	return closeErr
 }
@@ -0,0 +1,206 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/tracing"
+	"order-service/pkg/interfaces"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// InvalidationPublisher публикует инвалидации кеша в общий топик
+type InvalidationPublisher struct {
+	writer     *kafka.Writer
+	instanceID string
+}
+
+func NewInvalidationPublisher(brokers []string, topic, instanceID string) *InvalidationPublisher {
+	return &InvalidationPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.LeastBytes{},
+			RequiredAcks:           kafka.RequireOne,
+			AllowAutoTopicCreation: true,
+		},
+		instanceID: instanceID,
+	}
+}
+
+// PublishInvalidation отправляет инвалидацию от имени текущей реплики
+func (p *InvalidationPublisher) PublishInvalidation(ctx context.Context, invalidation models.CacheInvalidation) (err error) {
+	invalidation.Origin = p.instanceID
+	if invalidation.At.IsZero() {
+		invalidation.At = time.Now()
+	}
+
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return err
+	}
+
+	msg := kafka.Message{Value: payload}
+	ctx, span := StartProducerSpan(ctx, p.writer.Topic, &msg)
+	defer func() { tracing.End(span, err) }()
+
+	writeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := p.writer.WriteMessages(writeCtx, msg); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Cache invalidation published",
+		"keys", invalidation.Keys,
+		"prefix", invalidation.Prefix,
+		"flush", invalidation.Flush,
+		"reason", invalidation.Reason)
+	return nil
+}
+
+func (p *InvalidationPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// InvalidationSubscriber применяет инвалидации других реплик к локальному кешу.
+// Каждая реплика читает топик в собственной группе, поэтому получает все сообщения.
+type InvalidationSubscriber struct {
+	brokers    []string
+	topic      string
+	groupID    string
+	instanceID string
+	cache      interfaces.CacheRepository
+	isRunning  bool
+	reader     *kafka.Reader
+	readLoop
+}
+
+// NewInvalidationSubscriber создает подписчика; группа потребителей уникальна для реплики
+func NewInvalidationSubscriber(brokers []string, topic, groupPrefix, instanceID string, cache interfaces.CacheRepository) *InvalidationSubscriber {
+	return &InvalidationSubscriber{
+		brokers:    brokers,
+		topic:      topic,
+		groupID:    groupPrefix + "-" + instanceID,
+		instanceID: instanceID,
+		cache:      cache,
+	}
+}
+
+func (s *InvalidationSubscriber) Start(ctx context.Context) error {
+	if s.isRunning {
+		if !s.readLoop.stopping.Load() {
+			return nil
+		}
+		// Shutdown не дождался горутины: второй reader читал бы параллельно с ней
+		if !s.readLoop.finished() {
+			return errReadLoopStopping
+		}
+	}
+
+	s.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers: s.brokers,
+		Topic:   s.topic,
+		GroupID: s.groupID,
+		// Новой группе не нужны инвалидации, отправленные до запуска реплики
+		StartOffset:     kafka.LastOffset,
+		MaxWait:         500 * time.Millisecond,
+		ReadLagInterval: -1,
+	})
+
+	slog.Info("Cache invalidation subscription started",
+		"topic", s.topic,
+		"group_id", s.groupID)
+
+	s.isRunning = true
+
+	reader := s.reader
+	s.readLoop.start("cache invalidation subscriber", func() error { return s.processMessages(ctx, reader) })
+
+	return nil
+}
+
+// processMessages читает инвалидации до отмены ctx или закрытия reader; ошибка означает,
+// что чтение прекратилось само
+func (s *InvalidationSubscriber) processMessages(ctx context.Context, reader *kafka.Reader) error {
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				slog.Info("Cache invalidation subscriber stopped")
+				return nil
+			}
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("kafka reader closed: %w", err)
+			}
+
+			slog.Error("Error reading invalidation from Kafka", "error", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		var invalidation models.CacheInvalidation
+		if err := json.Unmarshal(msg.Value, &invalidation); err != nil {
+			slog.Error("Failed to parse cache invalidation", "error", err, "offset", msg.Offset)
+			continue
+		}
+
+		s.Apply(invalidation)
+	}
+}
+
+// Apply применяет инвалидацию к локальному кешу; собственные сообщения реплики игнорируются
+func (s *InvalidationSubscriber) Apply(invalidation models.CacheInvalidation) {
+	if invalidation.Origin == s.instanceID {
+		return
+	}
+
+	switch {
+	case invalidation.Flush:
+		s.cache.Flush()
+	case invalidation.Prefix != "":
+		s.cache.DeletePrefix(invalidation.Prefix)
+	}
+	for _, key := range invalidation.Keys {
+		s.cache.Delete(key)
+	}
+
+	slog.Info("Cache invalidation applied",
+		"origin", invalidation.Origin,
+		"keys", invalidation.Keys,
+		"prefix", invalidation.Prefix,
+		"flush", invalidation.Flush,
+		"reason", invalidation.Reason)
+}
+
+func (s *InvalidationSubscriber) Shutdown(ctx context.Context) error {
+	if !s.isRunning || s.reader == nil {
+		return nil
+	}
+
+	slog.Info("Cache invalidation subscriber shutting down")
+
+	s.readLoop.stop()
+	closeErr := s.reader.Close()
+	if closeErr != nil {
+		slog.Error("Kafka reader close failed", "error", closeErr)
+		closeErr = fmt.Errorf("failed to close kafka reader: %w", closeErr)
+	}
+	waitErr := s.readLoop.wait(ctx)
+	if waitErr != nil {
+		// Горутина еще работает со своим reader: запуск не завершен, и Start откажет,
+		// пока она не остановится
+		return errors.Join(closeErr, waitErr)
+	}
+
+	s.isRunning = false
+	s.reader = nil
+	return closeErr
+}
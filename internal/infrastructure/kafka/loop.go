@@ -0,0 +1,78 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// errReadLoopStopping возвращает Start, пока горутина прежнего запуска еще не завершилась
+var errReadLoopStopping = errors.New("previous read loop is still stopping")
+
+// readLoop — горутина чтения сообщений потребителя. Остановка дожидается ее завершения,
+// а если горутина завершилась сама (ошибка или паника), отказ приходит в канал Failures.
+type readLoop struct {
+	stopping atomic.Bool
+	done     chan struct{}
+	failures chan error
+}
+
+// start запускает run в горутине; name попадает в журнал и текст отказа
+func (l *readLoop) start(name string, run func() error) {
+	l.stopping.Store(false)
+	done := make(chan struct{})
+	failures := make(chan error, 1)
+	l.done, l.failures = done, failures
+
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Kafka consumer panicked", "consumer", name, "panic", r, "stack", string(debug.Stack()))
+				failures <- fmt.Errorf("%s panicked: %v", name, r)
+			}
+		}()
+		if err := run(); err != nil && !l.stopping.Load() {
+			failures <- fmt.Errorf("%s stopped: %w", name, err)
+		}
+	}()
+}
+
+// stop отмечает штатную остановку: ошибки чтения после нее не считаются отказом
+func (l *readLoop) stop() {
+	l.stopping.Store(true)
+}
+
+// wait дожидается завершения горутины, но не дольше ctx
+func (l *readLoop) wait(ctx context.Context) error {
+	if l.done == nil {
+		return nil
+	}
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("read loop did not finish: %w", ctx.Err())
+	}
+}
+
+// finished сообщает, что горутина последнего запуска завершилась или не запускалась
+func (l *readLoop) finished() bool {
+	if l.done == nil {
+		return true
+	}
+	select {
+	case <-l.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Failures возвращает канал отказов текущего запуска
+func (l *readLoop) Failures() <-chan error {
+	return l.failures
+}
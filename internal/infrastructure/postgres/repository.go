@@ -3,13 +3,22 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"order-service/internal/domain/models"
+	"reflect"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// execer — общее для *sql.DB и *sql.Tx подмножество методов
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 type PostgresRepository struct {
 	db *sql.DB
 }
@@ -20,12 +29,23 @@ func NewPostgresRepository(db *sql.DB) *PostgresRepository {
 	}
 }
 
-func (r *PostgresRepository) SaveOrder(ctx context.Context, order models.Order) error {
+// SaveOrder сохраняет заказ вместе со снимком в order_cache в одной транзакции. Существующий заказ
+// перезаписывается (OrderIngestUpdated), только если его содержимое отличается от снимка;
+// иначе возвращается OrderIngestDuplicate.
+func (r *PostgresRepository) SaveOrder(ctx context.Context, order models.Order) (result models.IngestResult, err error) {
+	ctx, span := startMethodSpan(ctx, "SaveOrder")
+	defer func() { endSpan(span, err) }()
+
+	orderData, err := json.Marshal(order)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal order: %w", err)
+	}
+
 	// Начинаем транзакцию
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		slog.Error("Failed to begin transaction", "error", err)
-		return err
+		slog.ErrorContext(ctx, "Failed to begin transaction", "error", err)
+		return "", err
 	}
 
 	// Переменная для определения, нужно ли делать rollback
@@ -35,34 +55,66 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order models.Order)
 	defer func() {
 		if !committed {
 			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				slog.Error("Failed to rollback transaction", "error", rollbackErr)
+				slog.ErrorContext(ctx, "Failed to rollback transaction", "error", rollbackErr)
 			}
 		}
 	}()
 
 	// 1. Сохраняем основную информацию о заказе
-	slog.Info("Saving order to database", "orderUID", order.OrderUID)
+	slog.InfoContext(ctx, "Saving order to database", "orderUID", order.OrderUID)
 
-	_, err = tx.ExecContext(ctx, `
+	// Блокируем существующий заказ, чтобы параллельная корректировка не перемешала товары,
+	// и читаем его снимок, чтобы отличить корректировку от повторной доставки
+	var (
+		lockedUID string
+		snapshot  []byte
+	)
+	queryCtx, querySpan := startQuerySpan(ctx, "SELECT", "orders")
+	err = tx.QueryRowContext(queryCtx, `
+		SELECT o.order_uid, c.data FROM orders o
+		LEFT JOIN order_cache c ON c.order_uid = o.order_uid
+		WHERE o.order_uid = $1 FOR UPDATE OF o
+	`, order.OrderUID).Scan(&lockedUID, &snapshot)
+	endSpan(querySpan, err)
+
+	if err != nil && err != sql.ErrNoRows {
+		slog.ErrorContext(ctx, "Failed to check order existence", "error", err, "orderUID", order.OrderUID)
+		return "", err
+	}
+	existed := err == nil
+
+	if existed && sameOrderData(snapshot, orderData) {
+		slog.InfoContext(ctx, "Order unchanged, skipping save", "orderUID", order.OrderUID)
+		return models.OrderIngestDuplicate, nil
+	}
+
+	queryCtx, querySpan = startQuerySpan(ctx, "INSERT", "orders")
+	_, err = tx.ExecContext(queryCtx, `
 		INSERT INTO orders (
 			order_uid, track_number, entry, locale, internal_signature, 
 			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-		) ON CONFLICT (order_uid) DO NOTHING;
+		) ON CONFLICT (order_uid) DO UPDATE SET
+			track_number = $2, entry = $3, locale = $4, internal_signature = $5,
+			customer_id = $6, delivery_service = $7, shardkey = $8, sm_id = $9,
+			date_created = $10, oof_shard = $11;
 	`,
 		order.OrderUID, order.TrackNumber, order.Entry, order.Locale,
 		order.InternalSignature, order.CustomerID, order.DeliveryService,
 		order.Shardkey, order.SmID, order.DateCreated, order.OofShard,
 	)
 
+	endSpan(querySpan, err)
+
 	if err != nil {
-		slog.Error("Failed to insert order", "error", err, "orderUID", order.OrderUID)
-		return err
+		slog.ErrorContext(ctx, "Failed to insert order", "error", err, "orderUID", order.OrderUID)
+		return "", err
 	}
 
 	// 2. Сохраняем информацию о доставке
-	_, err = tx.ExecContext(ctx, `
+	queryCtx, querySpan = startQuerySpan(ctx, "INSERT", "delivery")
+	_, err = tx.ExecContext(queryCtx, `
 		INSERT INTO delivery (
 			order_uid, name, phone, zip, city, address, region, email
 		) VALUES (
@@ -76,13 +128,16 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order models.Order)
 		order.Delivery.Region, order.Delivery.Email,
 	)
 
+	endSpan(querySpan, err)
+
 	if err != nil {
-		slog.Error("Failed to insert delivery", "error", err, "orderUID", order.OrderUID)
-		return err
+		slog.ErrorContext(ctx, "Failed to insert delivery", "error", err, "orderUID", order.OrderUID)
+		return "", err
 	}
 
 	// 3. Сохраняем информацию об оплате
-	_, err = tx.ExecContext(ctx, `
+	queryCtx, querySpan = startQuerySpan(ctx, "INSERT", "payment")
+	_, err = tx.ExecContext(queryCtx, `
 		INSERT INTO payment (
 			transaction_id, order_uid, request_id, currency, provider,
 			amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
@@ -98,14 +153,30 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order models.Order)
 		order.Payment.GoodsTotal, order.Payment.CustomFee,
 	)
 
+	endSpan(querySpan, err)
+
 	if err != nil {
-		slog.Error("Failed to insert payment", "error", err, "orderUID", order.OrderUID)
-		return err
+		slog.ErrorContext(ctx, "Failed to insert payment", "error", err, "orderUID", order.OrderUID)
+		return "", err
+	}
+
+	// 4. Сохраняем товары; при корректировке удаляем позиции, которых больше нет в заказе
+	if existed {
+		queryCtx, querySpan = startQuerySpan(ctx, "DELETE", "items")
+		_, err = tx.ExecContext(queryCtx, `
+			DELETE FROM items WHERE order_uid = $1
+		`, order.OrderUID)
+		endSpan(querySpan, err)
+
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to delete previous items", "error", err, "orderUID", order.OrderUID)
+			return "", err
+		}
 	}
 
-	// 4. Сохраняем товары
 	for _, item := range order.Items {
-		_, err = tx.ExecContext(ctx, `
+		queryCtx, querySpan = startQuerySpan(ctx, "INSERT", "items")
+		_, err = tx.ExecContext(queryCtx, `
 			INSERT INTO items (
 				chrt_id, order_uid, track_number, price, rid, name,
 				sale, size, total_price, nm_id, brand, status
@@ -119,26 +190,53 @@ func (r *PostgresRepository) SaveOrder(ctx context.Context, order models.Order)
 			item.Rid, item.Name, item.Sale, item.Size, item.TotalPrice,
 			item.NmID, item.Brand, item.Status,
 		)
+		endSpan(querySpan, err)
 
 		if err != nil {
-			slog.Error("Failed to insert item", "error", err, "orderUID", order.OrderUID, "chrtID", item.ChrtID)
-			return err
+			slog.ErrorContext(ctx, "Failed to insert item", "error", err, "orderUID", order.OrderUID, "chrtID", item.ChrtID)
+			return "", err
 		}
 	}
 
+	// 5. Обновляем снимок в той же транзакции, чтобы он всегда соответствовал таблицам
+	if err = upsertOrderCache(ctx, tx, order.OrderUID, orderData); err != nil {
+		return "", err
+	}
+
 	// Фиксируем транзакцию
 	if err = tx.Commit(); err != nil {
-		slog.Error("Failed to commit transaction", "error", err, "orderUID", order.OrderUID)
-		return err
+		slog.ErrorContext(ctx, "Failed to commit transaction", "error", err, "orderUID", order.OrderUID)
+		return "", err
 	}
 	committed = true
-	slog.Info("Order successfully saved", "orderUID", order.OrderUID)
-	return nil
+	slog.InfoContext(ctx, "Order successfully saved", "orderUID", order.OrderUID, "updated", existed)
+	if existed {
+		return models.OrderIngestUpdated, nil
+	}
+	return models.OrderIngestCreated, nil
+}
+
+// sameOrderData сравнивает снимок заказа из order_cache с сериализованным заказом; JSONB не сохраняет
+// форматирование и порядок ключей, поэтому сравниваются разобранные значения
+func sameOrderData(snapshot, data []byte) bool {
+	if len(snapshot) == 0 {
+		return false
+	}
+
+	var stored, current any
+	if json.Unmarshal(snapshot, &stored) != nil || json.Unmarshal(data, &current) != nil {
+		return false
+	}
+	return reflect.DeepEqual(stored, current)
 }
 
 func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	ctx, span := startMethodSpan(ctx, "GetOrder")
+	defer span.End()
+
 	// Запрос для получения основной информации о заказе
-	orderRow := r.db.QueryRowContext(ctx, `
+	queryCtx, querySpan := startQuerySpan(ctx, "SELECT", "orders")
+	orderRow := r.db.QueryRowContext(queryCtx, `
 		SELECT 
 			order_uid, track_number, entry, locale, internal_signature, 
 			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
@@ -153,12 +251,13 @@ func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*mo
 		&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
 		&order.Shardkey, &order.SmID, &dateCreated, &order.OofShard,
 	)
+	endSpan(querySpan, err)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			slog.Info("Order not found in database", "orderUID", orderUID)
+			slog.InfoContext(ctx, "Order not found in database", "orderUID", orderUID)
 		} else {
-			slog.Error("Error querying order", "error", err, "orderUID", orderUID)
+			slog.ErrorContext(ctx, "Error querying order", "error", err, "orderUID", orderUID)
 		}
 		return nil, err
 	}
@@ -166,12 +265,13 @@ func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*mo
 	// Парсинг даты создания
 	order.DateCreated, err = time.Parse(time.RFC3339, dateCreated)
 	if err != nil {
-		slog.Error("Error parsing date", "error", err, "date", dateCreated)
+		slog.ErrorContext(ctx, "Error parsing date", "error", err, "date", dateCreated)
 		return nil, err
 	}
 
 	// Запрос для получения информации о доставке
-	deliveryRow := r.db.QueryRowContext(ctx, `
+	queryCtx, querySpan = startQuerySpan(ctx, "SELECT", "delivery")
+	deliveryRow := r.db.QueryRowContext(queryCtx, `
 		SELECT 
 			name, phone, zip, city, address, region, email
 		FROM delivery 
@@ -183,14 +283,16 @@ func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*mo
 		&order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region,
 		&order.Delivery.Email,
 	)
+	endSpan(querySpan, err)
 
 	if err != nil {
-		slog.Error("Error querying delivery", "error", err, "orderUID", orderUID)
+		slog.ErrorContext(ctx, "Error querying delivery", "error", err, "orderUID", orderUID)
 		return nil, err
 	}
 
 	// Запрос для получения информации об оплате
-	paymentRow := r.db.QueryRowContext(ctx, `
+	queryCtx, querySpan = startQuerySpan(ctx, "SELECT", "payment")
+	paymentRow := r.db.QueryRowContext(queryCtx, `
 		SELECT 
 			transaction_id, request_id, currency, provider, amount,
 			payment_dt, bank, delivery_cost, goods_total, custom_fee
@@ -204,14 +306,17 @@ func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*mo
 		&order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal,
 		&order.Payment.CustomFee,
 	)
+	endSpan(querySpan, err)
 
 	if err != nil {
-		slog.Error("Error querying payment", "error", err, "orderUID", orderUID)
+		slog.ErrorContext(ctx, "Error querying payment", "error", err, "orderUID", orderUID)
 		return nil, err
 	}
 
 	// Запрос для получения товаров
-	itemsRows, err := r.db.QueryContext(ctx, `
+	queryCtx, querySpan = startQuerySpan(ctx, "SELECT", "items")
+	defer querySpan.End()
+	itemsRows, err := r.db.QueryContext(queryCtx, `
 		SELECT 
 			chrt_id, track_number, price, rid, name, sale,
 			size, total_price, nm_id, brand, status
@@ -220,12 +325,13 @@ func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*mo
 	`, orderUID)
 
 	if err != nil {
-		slog.Error("Error querying items", "error", err, "orderUID", orderUID)
+		endSpan(querySpan, err)
+		slog.ErrorContext(ctx, "Error querying items", "error", err, "orderUID", orderUID)
 		return nil, err
 	}
 	defer func() {
 		if err := itemsRows.Close(); err != nil {
-			slog.Error("failed to close items rows", "error", err)
+			slog.ErrorContext(ctx, "failed to close items rows", "error", err)
 		}
 	}()
 
@@ -237,62 +343,183 @@ func (r *PostgresRepository) GetOrder(ctx context.Context, orderUID string) (*mo
 			&item.Name, &item.Sale, &item.Size, &item.TotalPrice,
 			&item.NmID, &item.Brand, &item.Status,
 		); err != nil {
-			slog.Error("Error scanning item", "error", err)
+			slog.ErrorContext(ctx, "Error scanning item", "error", err)
 			return nil, err
 		}
 		order.Items = append(order.Items, item)
 	}
 
 	if err = itemsRows.Err(); err != nil {
-		slog.Error("Error iterating items rows", "error", err)
+		slog.ErrorContext(ctx, "Error iterating items rows", "error", err)
 		return nil, err
 	}
 
-	slog.Info("Order retrieved successfully", "orderUID", orderUID)
+	slog.InfoContext(ctx, "Order retrieved successfully", "orderUID", orderUID)
 	return &order, nil
 }
 
-func (r *PostgresRepository) GetAllOrders() ([]string, error) {
-	rows, err := r.db.Query("SELECT order_uid FROM orders")
+// GetOrders загружает несколько заказов одним запросом; отсутствующие заказы в результат не попадают
+func (r *PostgresRepository) GetOrders(ctx context.Context, orderUIDs []string) ([]models.Order, error) {
+	if len(orderUIDs) == 0 {
+		return nil, nil
+	}
+
+	ctx, span := startQuerySpan(ctx, "SELECT", "orders")
+	defer span.End()
+
+	// Товары собираются в JSON-массив с теми же ключами, что и у models.Item
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+			o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+			d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+			p.transaction_id, p.request_id, p.currency, p.provider, p.amount,
+			p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
+			COALESCE((
+				SELECT json_agg(json_build_object(
+					'chrt_id', i.chrt_id, 'track_number', i.track_number, 'price', i.price,
+					'rid', i.rid, 'name', i.name, 'sale', i.sale, 'size', i.size,
+					'total_price', i.total_price, 'nm_id', i.nm_id, 'brand', i.brand,
+					'status', i.status))
+				FROM items i
+				WHERE i.order_uid = o.order_uid
+			), '[]')
+		FROM orders o
+		JOIN delivery d ON d.order_uid = o.order_uid
+		JOIN payment p ON p.order_uid = o.order_uid
+		WHERE o.order_uid = ANY($1)
+	`, pq.Array(orderUIDs))
 	if err != nil {
-		slog.Error("Failed to query orders", "error", err)
+		endSpan(span, err)
+		slog.ErrorContext(ctx, "Error querying orders batch", "error", err, "count", len(orderUIDs))
 		return nil, err
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
-			slog.Error("failed to close rows", "error", err)
+			slog.ErrorContext(ctx, "failed to close rows", "error", err)
 		}
 	}()
 
-	var orders []string
+	orders := make([]models.Order, 0, len(orderUIDs))
 	for rows.Next() {
-		var orderUID string
-		if err := rows.Scan(&orderUID); err != nil {
-			slog.Error("Failed to scan order_uid", "error", err)
+		var order models.Order
+		var dateCreated string
+		var items []byte
+		if err := rows.Scan(
+			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale,
+			&order.InternalSignature, &order.CustomerID, &order.DeliveryService,
+			&order.Shardkey, &order.SmID, &dateCreated, &order.OofShard,
+			&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip,
+			&order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region,
+			&order.Delivery.Email,
+			&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency,
+			&order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDt,
+			&order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal,
+			&order.Payment.CustomFee,
+			&items,
+		); err != nil {
+			slog.ErrorContext(ctx, "Error scanning orders batch", "error", err)
+			return nil, err
+		}
+
+		order.DateCreated, err = time.Parse(time.RFC3339, dateCreated)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error parsing date", "error", err, "date", dateCreated)
+			return nil, err
+		}
+
+		if err := json.Unmarshal(items, &order.Items); err != nil {
+			slog.ErrorContext(ctx, "Error decoding order items", "error", err, "orderUID", order.OrderUID)
 			return nil, err
 		}
-		orders = append(orders, orderUID)
+
+		orders = append(orders, order)
 	}
 
-	if err = rows.Err(); err != nil {
-		slog.Error("Error iterating order rows", "error", err)
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "Error iterating orders batch", "error", err)
 		return nil, err
 	}
 
+	slog.InfoContext(ctx, "Orders batch retrieved", "requested", len(orderUIDs), "found", len(orders))
 	return orders, nil
 }
 
+// ListOrderSnapshots возвращает страницу заказов (от новых к старым) вместе со снимком из order_cache.
+// Используется keyset-пагинация по (date_created, order_uid), поэтому глубокие страницы не дорожают.
+// Заказы без date_created сервис не принимает (см. models.Order.Validate, NOT NULL в схеме);
+// строки без даты, записанные в обход сервиса, в список и прогрев не попадают.
+func (r *PostgresRepository) ListOrderSnapshots(ctx context.Context, query models.OrderPageQuery) ([]models.OrderSnapshot, error) {
+	conditions := []string{"o.date_created IS NOT NULL"}
+	args := []any{}
+
+	if !query.Since.IsZero() {
+		args = append(args, query.Since)
+		conditions = append(conditions, fmt.Sprintf("o.date_created >= $%d", len(args)))
+	}
+	if query.AfterUID != "" {
+		args = append(args, query.AfterCreated, query.AfterUID)
+		conditions = append(conditions, fmt.Sprintf("(o.date_created, o.order_uid) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, query.Limit)
+
+	ctx, span := startQuerySpan(ctx, "SELECT", "orders")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT o.order_uid, o.date_created, c.data
+		FROM orders o
+		LEFT JOIN order_cache c ON c.order_uid = o.order_uid
+		WHERE %s
+		ORDER BY o.date_created DESC, o.order_uid DESC
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		endSpan(span, err)
+		slog.ErrorContext(ctx, "Failed to query order snapshots", "error", err)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.ErrorContext(ctx, "failed to close rows", "error", err)
+		}
+	}()
+
+	snapshots := make([]models.OrderSnapshot, 0, query.Limit)
+	for rows.Next() {
+		var snapshot models.OrderSnapshot
+		if err := rows.Scan(&snapshot.OrderUID, &snapshot.DateCreated, &snapshot.Data); err != nil {
+			slog.ErrorContext(ctx, "Failed to scan order snapshot", "error", err)
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "Error iterating order snapshot rows", "error", err)
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
 // Новые методы для кеширования полных данных заказа
-func (r *PostgresRepository) CacheOrderData(orderUID string, orderData []byte) error {
-	_, err := r.db.Exec(`
+func (r *PostgresRepository) CacheOrderData(ctx context.Context, orderUID string, orderData []byte) error {
+	return upsertOrderCache(ctx, r.db, orderUID, orderData)
+}
+
+func upsertOrderCache(ctx context.Context, db execer, orderUID string, orderData []byte) error {
+	ctx, span := startQuerySpan(ctx, "INSERT", "order_cache")
+	_, err := db.ExecContext(ctx, `
 		INSERT INTO order_cache (order_uid, data, created_at) 
 		VALUES ($1, $2, NOW())
 		ON CONFLICT (order_uid) DO UPDATE SET 
 		data = $2, created_at = NOW()
 	`, orderUID, orderData)
+	endSpan(span, err)
 
 	if err != nil {
-		slog.Error("Failed to cache order data", "error", err, "orderUID", orderUID)
+		slog.ErrorContext(ctx, "Failed to cache order data", "error", err, "orderUID", orderUID)
 	}
 
 	return err
@@ -300,9 +527,11 @@ func (r *PostgresRepository) CacheOrderData(orderUID string, orderData []byte) e
 
 func (r *PostgresRepository) GetCachedOrderData(orderUID string) ([]byte, error) {
 	var data []byte
-	err := r.db.QueryRow(`
+	ctx, span := startQuerySpan(context.Background(), "SELECT", "order_cache")
+	err := r.db.QueryRowContext(ctx, `
 		SELECT data FROM order_cache WHERE order_uid = $1
 	`, orderUID).Scan(&data)
+	endSpan(span, err)
 
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -314,21 +543,92 @@ func (r *PostgresRepository) GetCachedOrderData(orderUID string) ([]byte, error)
 	return data, nil
 }
 
-// ConnectToDB устанавливает подключение к базе данных
-func ConnectToDB(connStr string) (*sql.DB, error) {
+// ConnectOptions — параметры пула соединений и повторных попыток подключения
+type ConnectOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// PingTimeout ограничивает одну попытку подключения
+	PingTimeout time.Duration
+	// RetryDelay — первая пауза между попытками; каждая следующая вдвое дольше, но не больше MaxRetryDelay
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+// DefaultConnectOptions возвращает параметры по умолчанию
+func DefaultConnectOptions() ConnectOptions {
+	return ConnectOptions{
+		MaxOpenConns:    25,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 5 * time.Minute,
+		ConnMaxIdleTime: 2 * time.Minute,
+		PingTimeout:     5 * time.Second,
+		RetryDelay:      500 * time.Millisecond,
+		MaxRetryDelay:   10 * time.Second,
+	}
+}
+
+// ConnectToDB устанавливает подключение к базе данных.
+// Пока PostgreSQL не готов, попытки повторяются с экспоненциальной задержкой до отмены ctx.
+func ConnectToDB(ctx context.Context, connStr string, opts ConnectOptions) (*sql.DB, error) {
+	db, err := OpenDB(connStr, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := WaitReady(ctx, db, opts); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenDB создает пул соединений без подключения к серверу; готовность проверяет WaitReady
+func OpenDB(connStr string, opts ConnectOptions) (*sql.DB, error) {
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, err
 	}
 
 	// Устанавливаем параметры пула соединений
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	return db, nil
+}
 
-	if err := db.Ping(); err != nil {
-		return nil, err
+// WaitReady ждет готовности PostgreSQL, повторяя попытки с экспоненциальной задержкой до отмены ctx
+func WaitReady(ctx context.Context, db *sql.DB, opts ConnectOptions) error {
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = DefaultConnectOptions().RetryDelay
 	}
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx, db, opts.PingTimeout)
+		if err == nil {
+			return nil
+		}
 
-	return db, nil
+		slog.WarnContext(ctx, "Database is not ready, retrying", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database is not ready after %d attempts: %w", attempt, err)
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if opts.MaxRetryDelay > 0 && delay > opts.MaxRetryDelay {
+			delay = opts.MaxRetryDelay
+		}
+	}
+}
+
+func ping(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return db.PingContext(ctx)
 }
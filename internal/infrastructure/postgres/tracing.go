@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-service/internal/tracing"
+)
+
+// startQuerySpan открывает span одного запроса, например "INSERT delivery"
+func startQuerySpan(ctx context.Context, operation, table string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, operation+" "+table,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+		),
+	)
+}
+
+// startMethodSpan открывает span метода репозитория, объединяющий его запросы
+func startMethodSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "PostgresRepository."+method,
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
+}
+
+// endSpan завершает span; отсутствие строки ошибкой запроса не считается
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Принимаем идентификатор клиента, только если он короткий и безопасен для журналов
+const maxRequestIDLength = 128
+
+type attrsKey struct{}
+
+type requestIDKey struct{}
+
+// WithAttrs возвращает контекст, записи журнала из которого получат дополнительные атрибуты
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// WithRequestID сохраняет идентификатор запроса в контексте и добавляет его ко всем записям журнала
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return WithAttrs(ctx, slog.String("request_id", requestID))
+}
+
+// RequestIDOrNew возвращает переданный идентификатор запроса, если он допустим, иначе создает новый.
+// Допустимы буквы, цифры, точка, дефис и подчеркивание.
+func RequestIDOrNew(candidate string) string {
+	if candidate == "" || len(candidate) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, c := range candidate {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+		default:
+			return uuid.NewString()
+		}
+	}
+	return candidate
+}
+
+// RequestIDFromContext возвращает идентификатор запроса или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ContextHandler дописывает к записям атрибуты, сохраненные в контексте через WithAttrs,
+// и идентификаторы текущей трассировки.
+// Атрибуты попадают в запись только при вызовах с контекстом: slog.InfoContext и т.п.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: next}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
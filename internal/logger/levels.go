@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// levelState — общий уровень и уровни компонентов; меняются на лету
+type levelState struct {
+	global     slog.LevelVar
+	components atomic.Pointer[map[string]slog.Level]
+	// minimum — самый подробный из уровней, чтобы Enabled отсекал записи до сборки Record
+	minimum slog.LevelVar
+}
+
+var levels = &levelState{}
+
+// SetLevel меняет общий уровень логирования без пересоздания логгера
+func SetLevel(value slog.Level) {
+	levels.global.Set(value)
+	levels.updateMinimum()
+}
+
+// Level возвращает общий уровень логирования
+func Level() slog.Level {
+	return levels.global.Level()
+}
+
+// SetComponentLevels заменяет уровни компонентов. Компонент — последний элемент пути пакета,
+// из которого сделана запись: kafka, http, grpc, postgres, cache, usecase, main и т.д.
+func SetComponentLevels(components map[string]slog.Level) {
+	copied := make(map[string]slog.Level, len(components))
+	for component, level := range components {
+		copied[component] = level
+	}
+	levels.components.Store(&copied)
+	levels.updateMinimum()
+}
+
+// ComponentLevels возвращает уровни компонентов
+func ComponentLevels() map[string]slog.Level {
+	current := levels.components.Load()
+	copied := make(map[string]slog.Level)
+	if current != nil {
+		for component, level := range *current {
+			copied[component] = level
+		}
+	}
+	return copied
+}
+
+func (s *levelState) updateMinimum() {
+	minimum := s.global.Level()
+	if components := s.components.Load(); components != nil {
+		for _, level := range *components {
+			if level < minimum {
+				minimum = level
+			}
+		}
+	}
+	s.minimum.Set(minimum)
+}
+
+// levelFor возвращает уровень для компонента записи с адресом pc
+func (s *levelState) levelFor(pc uintptr) slog.Level {
+	if components := s.components.Load(); components != nil && len(*components) > 0 {
+		if level, ok := (*components)[componentOf(pc)]; ok {
+			return level
+		}
+	}
+	return s.global.Level()
+}
+
+// levelHandler фильтрует записи по общему уровню и уровню компонента
+type levelHandler struct {
+	next slog.Handler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= levels.minimum.Level()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < levels.levelFor(record.PC) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name)}
+}
+
+// componentCache кеширует компонент по адресу вызова
+var componentCache sync.Map
+
+// componentOf определяет компонент по пакету функции, сделавшей запись:
+// order-service/internal/infrastructure/kafka.(*OrderKafkaConsumer).handleMessage → kafka
+func componentOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if component, ok := componentCache.Load(pc); ok {
+		return component.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	component := frame.Function
+	if slash := strings.LastIndex(component, "/"); slash >= 0 {
+		component = component[slash+1:]
+	}
+	if dot := strings.Index(component, "."); dot >= 0 {
+		component = component[:dot]
+	}
+
+	componentCache.Store(pc, component)
+	return component
+}
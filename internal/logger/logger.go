@@ -1,14 +1,99 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 )
 
+// Форматы вывода
+const (
+	FormatJSON   = "json"
+	FormatText   = "text"
+	FormatPretty = "pretty"
+)
+
+// Options — настройки логгера
+type Options struct {
+	Level slog.Level
+	// Format — json, text или pretty (читаемый вывод для консоли разработчика)
+	Format    string
+	AddSource bool
+	Output    io.Writer
+	// Components переопределяет уровень для отдельных компонентов, например kafka=debug
+	Components map[string]slog.Level
+	// Redact включает маскирование персональных данных и секретов; nil — без маскирования
+	Redact *RedactOptions
+}
+
+// InitLogger включает логгер по умолчанию — JSON в stdout с маскированием — до загрузки конфигурации
 func InitLogger() {
-	jsonHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     slog.LevelInfo,
-		AddSource: true,
-	})
-	slog.SetDefault(slog.New(jsonHandler))
+	if err := Setup(Options{Level: slog.LevelInfo, Format: FormatJSON, AddSource: true, Output: os.Stdout, Redact: &RedactOptions{}}); err != nil {
+		panic(err)
+	}
+}
+
+// Setup заменяет логгер по умолчанию; уровни можно менять и после, через SetLevel и SetComponentLevels
+func Setup(opts Options) error {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	// Уровни проверяет levelHandler, поэтому форматирующий обработчик пропускает все записи
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: opts.AddSource}
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(output, handlerOpts)
+	case FormatPretty:
+		handler = newPrettyHandler(output, handlerOpts)
+	default:
+		return fmt.Errorf("unknown log format %q: expected json, text or pretty", opts.Format)
+	}
+	if opts.Redact != nil {
+		handler = NewRedactingHandler(handler, *opts.Redact)
+	}
+
+	SetLevel(opts.Level)
+	SetComponentLevels(opts.Components)
+
+	// Атрибуты из контекста (request_id, смещение Kafka и т.п.) попадают во все записи
+	slog.SetDefault(slog.New(NewContextHandler(&levelHandler{next: handler})))
+	return nil
+}
+
+// ParseLevel разбирает уровень: debug, info, warn или error
+func ParseLevel(value string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q: expected debug, info, warn or error", value)
+	}
+	return parsed, nil
+}
+
+// ParseComponentLevels разбирает уровни компонентов вида "kafka=debug,postgres=warn"
+func ParseComponentLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		component, value, ok := strings.Cut(entry, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid component level %q: expected component=level", entry)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", component, err)
+		}
+		levels[component] = level
+	}
+	return levels, nil
 }
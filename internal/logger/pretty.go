@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI-цвета уровней
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorBlue   = "\033[34m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// prettyHandler выводит записи в читаемом для человека виде:
+// 15:04:05.000 INFO  message key=value
+// Цвета включаются, только если вывод — терминал.
+type prettyHandler struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	opts   slog.HandlerOptions
+	color  bool
+	prefix string // атрибуты из WithAttrs в готовом виде
+	groups []string
+}
+
+func newPrettyHandler(out io.Writer, opts *slog.HandlerOptions) *prettyHandler {
+	return &prettyHandler{
+		mu:    &sync.Mutex{},
+		out:   out,
+		opts:  *opts,
+		color: isTerminal(out),
+	}
+}
+
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (h *prettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minimum := slog.LevelInfo
+	if h.opts.Level != nil {
+		minimum = h.opts.Level.Level()
+	}
+	return level >= minimum
+}
+
+func (h *prettyHandler) Handle(_ context.Context, record slog.Record) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(h.paint(colorGray, record.Time.Format("15:04:05.000")))
+	buf.WriteByte(' ')
+	buf.WriteString(h.paint(levelColor(record.Level), fmt.Sprintf("%-5s", record.Level.String())))
+	buf.WriteByte(' ')
+	buf.WriteString(record.Message)
+
+	if h.opts.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		buf.WriteString(h.paint(colorGray, fmt.Sprintf(" (%s:%d)", filepath.Base(frame.File), frame.Line)))
+	}
+
+	buf.WriteString(h.prefix)
+	record.Attrs(func(attr slog.Attr) bool {
+		h.appendAttr(&buf, strings.Join(h.groups, "."), attr)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(buf.Bytes())
+	return err
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var buf bytes.Buffer
+	for _, attr := range attrs {
+		h.appendAttr(&buf, strings.Join(h.groups, "."), attr)
+	}
+	clone := *h
+	clone.prefix = h.prefix + buf.String()
+	return &clone
+}
+
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
+
+// appendAttr дописывает " key=value"; вложенные группы разворачиваются в ключи через точку
+func (h *prettyHandler) appendAttr(buf *bytes.Buffer, group string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := attr.Key
+	if group != "" {
+		key = group + "." + key
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		// Группа без имени встраивается в текущую
+		if attr.Key == "" {
+			key = group
+		}
+		for _, nested := range attr.Value.Group() {
+			h.appendAttr(buf, key, nested)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if attr.Value.Kind() == slog.KindTime {
+		value = attr.Value.Time().Format(time.RFC3339Nano)
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(h.paint(colorGray, key+"="))
+	buf.WriteString(value)
+}
+
+func (h *prettyHandler) paint(color, text string) string {
+	if !h.color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed
+	case level >= slog.LevelWarn:
+		return colorYellow
+	case level >= slog.LevelInfo:
+		return colorBlue
+	default:
+		return colorGray
+	}
+}
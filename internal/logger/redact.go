@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted заменяет значения чувствительных полей
+const Redacted = "[REDACTED]"
+
+// Замены для значений, найденных по шаблону
+const (
+	redactedEmail = "[EMAIL]"
+	redactedPhone = "[PHONE]"
+	redactedCard  = "[CARD]"
+)
+
+// DefaultSensitiveKeys — поля, значения которых не попадают в журнал: учетные данные
+// и персональные данные покупателя из заказа. Поле задается путем через точку и совпадает
+// с путем атрибута или вложенного ключа JSON целиком или с его частью: "delivery.name"
+// маскирует order.delivery.name, но не component.name.
+var DefaultSensitiveKeys = []string{
+	"password", "db_password", "secret", "client_secret",
+	"access_token", "refresh_token", "id_token", "api_key", "api_keys", "authorization", "cookie",
+	"delivery.name", "delivery.phone", "delivery.email", "delivery.address",
+	"delivery.zip", "delivery.city", "delivery.region",
+	"customer_id", "card_number",
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Телефон в международном формате: +7 (999) 123-45-67, +79991234567
+	phonePattern = regexp.MustCompile(`\+\d[\d ().-]{7,}\d`)
+	// Номер карты — 13–19 цифр, возможно через пробел или дефис; проверяется по алгоритму Луна
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// RedactOptions — настройки маскирования. Deny добавляет поля к DefaultSensitiveKeys,
+// Allow исключает поля из маскирования целиком, в том числе из поиска по шаблонам.
+// Поля в обоих списках задаются путями, как в DefaultSensitiveKeys.
+type RedactOptions struct {
+	Allow []string
+	Deny  []string
+}
+
+// redactor маскирует значения по путям полей и по шаблонам
+type redactor struct {
+	sensitive [][]string
+	allow     [][]string
+}
+
+func newRedactor(opts RedactOptions) *redactor {
+	r := &redactor{}
+	for _, key := range opts.Allow {
+		r.allow = append(r.allow, splitPath(key))
+	}
+	for _, keys := range [][]string{DefaultSensitiveKeys, opts.Deny} {
+		for _, key := range keys {
+			if path := splitPath(key); !r.isAllowed(path) {
+				r.sensitive = append(r.sensitive, path)
+			}
+		}
+	}
+	return r
+}
+
+func splitPath(key string) []string {
+	return strings.Split(strings.ToLower(key), ".")
+}
+
+// matchPath проверяет, что pattern встречается в path подряд идущими элементами
+func matchPath(path, pattern []string) bool {
+	for start := 0; start+len(pattern) <= len(path); start++ {
+		matched := true
+		for i, name := range pattern {
+			if !strings.EqualFold(path[start+i], name) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(path []string, patterns [][]string) bool {
+	for _, pattern := range patterns {
+		if matchPath(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) isSensitive(path []string) bool {
+	return matchAny(path, r.sensitive)
+}
+
+func (r *redactor) isAllowed(path []string) bool {
+	return matchAny(path, r.allow)
+}
+
+// attr возвращает атрибут с замаскированным значением; groups — группы, в которые вложен атрибут
+func (r *redactor) attr(groups []string, attr slog.Attr) slog.Attr {
+	path := append(groups[:len(groups):len(groups)], attr.Key)
+	if r.isAllowed(path) {
+		return attr
+	}
+	if r.isSensitive(path) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, r.text(value.String()))
+	case slog.KindGroup:
+		nested := value.Group()
+		redacted := make([]slog.Attr, len(nested))
+		for i, a := range nested {
+			redacted[i] = r.attr(path, a)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		// Тексты ошибок и сырые сообщения тоже могут содержать данные покупателя
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, r.text(v.Error()))
+		case []byte:
+			return slog.String(attr.Key, r.text(string(v)))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+// text маскирует значения чувствительных полей JSON, номера карт, телефоны и адреса почты
+func (r *redactor) text(s string) string {
+	if strings.Contains(s, `"`) {
+		s = r.json(s)
+	}
+	if strings.ContainsAny(s, "0123456789") {
+		s = cardPattern.ReplaceAllStringFunc(s, func(match string) string {
+			if luhnValid(match) {
+				return redactedCard
+			}
+			return match
+		})
+		s = phonePattern.ReplaceAllString(s, redactedPhone)
+	}
+	if strings.Contains(s, "@") {
+		s = emailPattern.ReplaceAllString(s, redactedEmail)
+	}
+	return s
+}
+
+// jsonFrame — объект или массив, внутри которого находится сканер JSON
+type jsonFrame struct {
+	object    bool
+	key       string
+	expectKey bool
+}
+
+// json маскирует значения чувствительных полей в тексте с JSON, в том числе обрезанном или
+// окруженном другим текстом: путь значения складывается из ключей объектов, в которые оно вложено
+func (r *redactor) json(s string) string {
+	var (
+		out    strings.Builder
+		frames []jsonFrame
+	)
+	// path возвращает путь текущего значения
+	path := func() []string {
+		var keys []string
+		for _, frame := range frames {
+			if frame.object {
+				keys = append(keys, frame.key)
+			}
+		}
+		return keys
+	}
+	// value записывает значение, маскируя его для чувствительного пути
+	value := func(raw string) {
+		if len(frames) > 0 {
+			if p := path(); !r.isAllowed(p) && r.isSensitive(p) {
+				out.WriteString(`"` + Redacted + `"`)
+				return
+			}
+		}
+		out.WriteString(raw)
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		top := len(frames) - 1
+		switch {
+		case c == '{' || c == '[':
+			frames = append(frames, jsonFrame{object: c == '{', expectKey: c == '{'})
+		case (c == '}' || c == ']') && top >= 0:
+			frames = frames[:top]
+		case c == ':' && top >= 0 && frames[top].object:
+			frames[top].expectKey = false
+		case c == ',' && top >= 0 && frames[top].object:
+			frames[top].expectKey = true
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(s))
+			if top >= 0 && frames[top].object && frames[top].expectKey {
+				frames[top].key = s[i+1 : max(end-1, i+1)]
+				out.WriteString(s[i:end])
+			} else {
+				value(s[i:end])
+			}
+			i = end
+			continue
+		case top >= 0 && !frames[top].expectKey && (c == '-' || c >= '0' && c <= '9' || c == 't' || c == 'f' || c == 'n'):
+			end := i
+			for end < len(s) && !strings.ContainsRune(",}] \t\r\n", rune(s[end])) {
+				end++
+			}
+			value(s[i:end])
+			i = end
+			continue
+		}
+		out.WriteByte(c)
+		i++
+	}
+	return out.String()
+}
+
+// luhnValid проверяет контрольную сумму номера карты, пропуская разделители
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits > 0 && sum%10 == 0
+}
+
+// RedactingHandler маскирует чувствительные данные в сообщении и атрибутах записей,
+// включая атрибуты, добавленные через WithAttrs и из контекста
+type RedactingHandler struct {
+	next     slog.Handler
+	redactor *redactor
+	// groups — группы, открытые через WithGroup: они входят в путь атрибутов
+	groups []string
+}
+
+func NewRedactingHandler(next slog.Handler, opts RedactOptions) *RedactingHandler {
+	return &RedactingHandler{next: next, redactor: newRedactor(opts)}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.text(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.attr(h.groups, attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactor.attr(h.groups, attr)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), redactor: h.redactor, groups: h.groups}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	groups := append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &RedactingHandler{next: h.next.WithGroup(name), redactor: h.redactor, groups: groups}
+}
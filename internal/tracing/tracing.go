@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "order-service"
+
+var cacheKey = attribute.Key("cache.key")
+
+// Config описывает экспорт трассировок
+type Config struct {
+	// Exporter: none, otlp, stdout или file. Адрес OTLP берется из стандартных
+	// переменных OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_TRACES_ENDPOINT и т.п.
+	Exporter string
+	// FilePath — файл для экспортера file; span'ы пишутся построчно в JSON
+	FilePath string
+	// SampleRatio — доля новых трассировок, попадающих в экспорт; решение родителя соблюдается
+	SampleRatio float64
+	// ServiceName по умолчанию; OTEL_SERVICE_NAME имеет приоритет
+	ServiceName string
+}
+
+// Init настраивает глобальный TracerProvider и распространение W3C Trace Context.
+// Распространение включается всегда: даже без экспорта входящие trace_id попадают в журналы.
+// Возвращаемая функция сбрасывает накопленные span'ы и закрывает экспортер.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		closeOut func() error
+		err      error
+	)
+	switch cfg.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracegrpc.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "file":
+		if cfg.FilePath == "" {
+			return nil, errors.New("tracing file exporter requires a file path")
+		}
+		file, openErr := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if openErr != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", openErr)
+		}
+		closeOut = file.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q: expected none, otlp, stdout or file", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME и OTEL_RESOURCE_ATTRIBUTES переопределяют имя из конфигурации
+	if fromEnv, envErr := resource.New(ctx, resource.WithFromEnv()); envErr == nil {
+		if merged, mergeErr := resource.Merge(res, fromEnv); mergeErr == nil {
+			res = merged
+		}
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeOut != nil {
+			err = errors.Join(err, closeOut())
+		}
+		return err
+	}, nil
+}
+
+// Tracer возвращает трассировщик сервиса из глобального TracerProvider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// End записывает ошибку в span и завершает его
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// CacheSpan открывает span операции с кешем. Интерфейс кеша не принимает контекст,
+// поэтому span'ы создаются в местах вызова, где контекст запроса известен.
+func CacheSpan(ctx context.Context, operation, key string) trace.Span {
+	_, span := Tracer().Start(ctx, "cache "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBOperationName(operation), cacheKey.String(key)),
+	)
+	return span
+}
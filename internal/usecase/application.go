@@ -2,51 +2,393 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
 
-	"order-service/internal/config"
 	"order-service/pkg/interfaces"
 )
 
+// DefaultStopTimeout ограничивает остановку компонента, если StopTimeout не задан ни для него, ни для приложения
+const DefaultStopTimeout = 10 * time.Second
+
+// Component — часть приложения с хуками запуска и остановки
+type Component struct {
+	Name string
+	// DependsOn — имена компонентов, которые запускаются раньше и останавливаются позже этого
+	DependsOn []string
+	// Start получает контекст работы приложения: он отменяется после остановки всех компонентов
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+	// StopTimeout ограничивает Stop; 0 — таймаут приложения по умолчанию
+	StopTimeout time.Duration
+	// Failures возвращает канал отказов текущего запуска (см. interfaces.FailureReporter); nil — компонент не отказывает
+	Failures func() <-chan error
+	// Restart — перезапуск после отказа; без него отказ завершает приложение
+	Restart RestartPolicy
+}
+
+// RestartPolicy — перезапуск компонента после отказа во время работы
+type RestartPolicy struct {
+	// MaxRestarts — сколько раз компонент можно перезапустить за время работы приложения
+	MaxRestarts int
+	// Backoff — пауза перед первым перезапуском; каждая следующая вдвое дольше, но не больше MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// failuresOf возвращает Failures компонента, если он сообщает об отказах
+func failuresOf(component any) func() <-chan error {
+	if reporter, ok := component.(interfaces.FailureReporter); ok {
+		return reporter.Failures
+	}
+	return nil
+}
+
+// ConsumerComponent оборачивает потребителя Kafka
+func ConsumerComponent(name string, consumer interfaces.KafkaConsumer, dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start:     consumer.Start,
+		Stop:      consumer.Shutdown,
+		Failures:  failuresOf(consumer),
+	}
+}
+
+// ServerComponent оборачивает HTTP- или gRPC-сервер
+func ServerComponent(name string, server interfaces.HTTPServer, dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start:     func(context.Context) error { return server.Start() },
+		Stop:      server.Shutdown,
+		Failures:  failuresOf(server),
+	}
+}
+
+// BackgroundJob запускает run в отдельной горутине; остановка отменяет контекст run и ждет ее завершения.
+// Ошибка или паника run до остановки считается отказом компонента.
+func BackgroundJob(name string, run func(ctx context.Context) error, dependsOn ...string) Component {
+	var (
+		cancel   context.CancelFunc
+		done     chan struct{}
+		failures chan error
+	)
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(ctx context.Context) error {
+			ctx, cancel = context.WithCancel(ctx)
+			done = make(chan struct{})
+			failures = make(chan error, 1)
+			go func() {
+				defer close(done)
+				defer func() {
+					if r := recover(); r != nil {
+						slog.Error("Background job panicked", "component", name, "panic", r, "stack", string(debug.Stack()))
+						failures <- fmt.Errorf("panic: %v", r)
+					}
+				}()
+				if err := run(ctx); err != nil && ctx.Err() == nil {
+					failures <- err
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		Failures: func() <-chan error { return failures },
+	}
+}
+
+// Application запускает компоненты в порядке зависимостей и останавливает их в обратном порядке.
+// Отказы компонентов во время работы обрабатывает супервизор: перезапускает компонент по RestartPolicy,
+// а если перезапуски исчерпаны — сообщает об отказе через Failed.
 type Application struct {
-	repo   interfaces.OrderRepository
-	cache  interfaces.CacheRepository
-	kafka  interfaces.KafkaConsumer
-	http   interfaces.HTTPServer
-	config *config.Config
+	stopTimeout time.Duration
+
+	mu         sync.Mutex
+	components []Component
+	started    []*runningComponent
+	runCtx     context.Context
+	cancelRun  context.CancelFunc
+
+	cancelSupervise context.CancelFunc
+	supervisors     sync.WaitGroup
+	failed          chan error
+}
+
+// runningComponent — запущенный компонент; running снимается, пока супервизор его перезапускает
+type runningComponent struct {
+	Component
+	running bool
 }
 
-func NewApplication(config *config.Config, repo interfaces.OrderRepository, cache interfaces.CacheRepository, kafka interfaces.KafkaConsumer, http interfaces.HTTPServer) *Application {
-	return &Application{
-		repo:   repo,
-		cache:  cache,
-		kafka:  kafka,
-		http:   http,
-		config: config,
+// NewApplication создает приложение; stopTimeout — таймаут остановки компонента по умолчанию
+func NewApplication(stopTimeout time.Duration) *Application {
+	if stopTimeout <= 0 {
+		stopTimeout = DefaultStopTimeout
 	}
+	return &Application{stopTimeout: stopTimeout, failed: make(chan error, 1)}
 }
 
+// Add регистрирует компонент. Компоненты без общих зависимостей запускаются в порядке регистрации.
+func (app *Application) Add(components ...Component) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.components = append(app.components, components...)
+}
+
+// Start запускает компоненты. Если какой-то из них не запустился или ctx отменен во время запуска,
+// уже запущенные компоненты останавливаются в обратном порядке.
 func (app *Application) Start(ctx context.Context) error {
-	if err := app.kafka.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start Kafka consumer: %w", err)
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.cancelRun != nil {
+		return errors.New("application is already started")
+	}
+	order, err := startOrder(app.components)
+	if err != nil {
+		return err
+	}
+
+	// Отмена ctx прерывает запуск, но не работу уже запущенного приложения
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	detach := context.AfterFunc(ctx, cancelRun)
+	defer detach()
+
+	for _, component := range order {
+		if runCtx.Err() != nil {
+			err = fmt.Errorf("startup interrupted before %s: %w", component.Name, context.Cause(ctx))
+			break
+		}
+
+		started := time.Now()
+		if component.Start != nil {
+			if startErr := component.Start(runCtx); startErr != nil {
+				err = fmt.Errorf("failed to start %s: %w", component.Name, startErr)
+				break
+			}
+		}
+		app.started = append(app.started, &runningComponent{Component: component, running: true})
+		slog.Info("Component started", "component", component.Name, "duration", time.Since(started))
+	}
+	if err == nil && !detach() {
+		err = fmt.Errorf("startup interrupted: %w", context.Cause(ctx))
 	}
 
-	if err := app.http.Start(); err != nil {
-		return fmt.Errorf("failed to start HTTP server: %w", err)
+	if err != nil {
+		slog.Error("Application startup failed, stopping started components", "error", err)
+		app.stopStarted(context.Background())
+		cancelRun()
+		return err
 	}
 
+	app.runCtx, app.cancelRun = runCtx, cancelRun
+
+	superviseCtx, cancelSupervise := context.WithCancel(runCtx)
+	app.cancelSupervise = cancelSupervise
+	for _, component := range app.started {
+		if component.Failures != nil {
+			app.supervisors.Add(1)
+			go app.supervise(superviseCtx, component)
+		}
+	}
 	return nil
 }
 
+// Failed возвращает канал, в который приходит отказ компонента, который не удалось перезапустить.
+// После него приложение нужно остановить через Shutdown.
+func (app *Application) Failed() <-chan error {
+	return app.failed
+}
+
+// supervise ждет отказа компонента и перезапускает его по RestartPolicy
+func (app *Application) supervise(ctx context.Context, component *runningComponent) {
+	defer app.supervisors.Done()
+
+	policy := component.Restart
+	backoff := policy.Backoff
+	restarts := 0
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case err = <-component.Failures():
+		}
+		slog.Error("Component failed", "component", component.Name, "error", err)
+
+		// Перезапуск, который сам не удался, тоже считается отказом
+		for {
+			if restarts >= policy.MaxRestarts {
+				app.fail(fmt.Errorf("%s: %w", component.Name, err))
+				return
+			}
+			restarts++
+
+			if stopErr := app.stop(context.Background(), component.Component); stopErr != nil {
+				slog.Error("Failed to stop component before restart", "component", component.Name, "error", stopErr)
+			}
+			component.running = false
+
+			slog.Warn("Restarting component", "component", component.Name, "attempt", restarts, "retry_in", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, max(policy.MaxBackoff, policy.Backoff))
+
+			if component.Start != nil {
+				if err = component.Start(app.runCtx); err != nil {
+					slog.Error("Component restart failed", "component", component.Name, "error", err)
+					continue
+				}
+			}
+			component.running = true
+			slog.Info("Component restarted", "component", component.Name, "attempt", restarts)
+			break
+		}
+	}
+}
+
+// fail сообщает об отказе приложения; сохраняется только первый отказ
+func (app *Application) fail(err error) {
+	select {
+	case app.failed <- err:
+	default:
+	}
+}
+
+// Shutdown останавливает запущенные компоненты в обратном порядке.
+// Каждый получает не больше своего StopTimeout, но все вместе — не больше времени ctx.
 func (app *Application) Shutdown(ctx context.Context) error {
-	if err := app.http.Shutdown(ctx); err != nil {
-		slog.Error("HTTP server shutdown error", "error", err)
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	// Супервизоры завершаются первыми, чтобы не перезапускать останавливаемые компоненты
+	if app.cancelSupervise != nil {
+		app.cancelSupervise()
+		app.supervisors.Wait()
 	}
 
-	if err := app.kafka.Shutdown(ctx); err != nil {
-		slog.Error("Kafka consumer shutdown error", "error", err)
+	err := app.stopStarted(ctx)
+	if app.cancelRun != nil {
+		app.cancelRun()
+		app.cancelRun = nil
 	}
+	return err
+}
 
-	return nil
+// stopStarted останавливает запущенные компоненты в обратном порядке и возвращает их ошибки
+func (app *Application) stopStarted(ctx context.Context) error {
+	var errs []error
+	for i := len(app.started) - 1; i >= 0; i-- {
+		component := app.started[i]
+		if !component.running {
+			continue
+		}
+		if err := app.stop(ctx, component.Component); err != nil {
+			slog.Error("Component shutdown error", "component", component.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", component.Name, err))
+			continue
+		}
+		slog.Info("Component stopped", "component", component.Name)
+	}
+	app.started = nil
+	return errors.Join(errs...)
+}
+
+// stop вызывает Stop с таймаутом компонента; зависший Stop не задерживает остановку остальных
+func (app *Application) stop(ctx context.Context, component Component) error {
+	if component.Stop == nil {
+		return nil
+	}
+
+	timeout := component.StopTimeout
+	if timeout <= 0 {
+		timeout = app.stopTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- component.Stop(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("did not stop in time: %w", ctx.Err())
+	}
+}
+
+// startOrder упорядочивает компоненты так, чтобы зависимости запускались раньше зависящих от них;
+// в остальном сохраняется порядок регистрации
+func startOrder(components []Component) ([]Component, error) {
+	byName := make(map[string]int, len(components))
+	for i, component := range components {
+		if component.Name == "" {
+			return nil, fmt.Errorf("component #%d has no name", i+1)
+		}
+		if _, ok := byName[component.Name]; ok {
+			return nil, fmt.Errorf("component %s is registered twice", component.Name)
+		}
+		byName[component.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(components))
+	order := make([]Component, 0, len(components))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		component := components[i]
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, component.Name), " -> "))
+		}
+
+		state[i] = visiting
+		for _, dependency := range component.DependsOn {
+			j, ok := byName[dependency]
+			if !ok {
+				return fmt.Errorf("component %s depends on unknown component %s", component.Name, dependency)
+			}
+			if err := visit(j, append(path, component.Name)); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, component)
+		return nil
+	}
+
+	for i := range components {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
 }
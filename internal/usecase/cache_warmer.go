@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"order-service/internal/domain/models"
+	"order-service/pkg/interfaces"
+)
+
+// WarmupOptions задает окно и параметры прогрева кеша
+type WarmupOptions struct {
+	BatchSize   int           // размер страницы, читаемой из БД
+	MaxAge      time.Duration // прогревать только заказы моложе MaxAge (0 — без ограничения)
+	MaxOrders   int           // прогревать не более MaxOrders самых свежих заказов (0 — без ограничения)
+	Concurrency int           // число параллельных догрузок заказов без снимка в order_cache
+	TTL         models.TTLPolicy
+}
+
+// CacheWarmer постранично загружает заказы из БД в кеш
+type CacheWarmer struct {
+	repo  interfaces.OrderRepository
+	cache interfaces.CacheRepository
+	opts  WarmupOptions
+	ttl   *models.TTLPolicyVar
+	load  interfaces.CacheLoader
+
+	running atomic.Bool
+	batches atomic.Int64
+	loaded  atomic.Int64
+	failed  atomic.Int64
+
+	mu         sync.RWMutex
+	rebuild    bool
+	startedAt  time.Time
+	finishedAt time.Time
+	lastErr    error
+}
+
+func NewCacheWarmer(repo interfaces.OrderRepository, cacheRepo interfaces.CacheRepository, opts WarmupOptions) *CacheWarmer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	ttl := models.NewTTLPolicyVar(opts.TTL)
+	return &CacheWarmer{
+		repo:  repo,
+		cache: cacheRepo,
+		opts:  opts,
+		ttl:   ttl,
+		load:  NewOrderCacheLoader(repo, ttl),
+	}
+}
+
+// SetTTLPolicy меняет политику TTL прогреваемых заказов; безопасен во время работы
+func (w *CacheWarmer) SetTTLPolicy(policy models.TTLPolicy) {
+	w.ttl.Store(policy)
+}
+
+// Run выполняет прогрев до конца окна или до отмены контекста
+func (w *CacheWarmer) Run(ctx context.Context) error {
+	return w.start(ctx, false)
+}
+
+// Rebuild прогревает кеш, игнорируя снимки order_cache: каждый заказ собирается заново
+// из исходных таблиц, а снимок перезаписывается. Нужен, если в кеш попали некорректные данные.
+func (w *CacheWarmer) Rebuild(ctx context.Context) error {
+	return w.start(ctx, true)
+}
+
+// TryStart запускает прогрев в фоне и сразу возвращает models.ErrWarmupInProgress, если он уже идет.
+// Результат прогрева отправляется в возвращаемый канал.
+func (w *CacheWarmer) TryStart(ctx context.Context, rebuild bool) (<-chan error, error) {
+	if !w.running.CompareAndSwap(false, true) {
+		return nil, models.ErrWarmupInProgress
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.execute(ctx, rebuild)
+	}()
+	return done, nil
+}
+
+func (w *CacheWarmer) start(ctx context.Context, rebuild bool) error {
+	if !w.running.CompareAndSwap(false, true) {
+		return models.ErrWarmupInProgress
+	}
+	return w.execute(ctx, rebuild)
+}
+
+// execute выполняет прогрев; флаг running к этому моменту уже установлен вызывающим
+func (w *CacheWarmer) execute(ctx context.Context, rebuild bool) error {
+	defer w.running.Store(false)
+
+	w.batches.Store(0)
+	w.loaded.Store(0)
+	w.failed.Store(0)
+	w.mu.Lock()
+	w.rebuild = rebuild
+	w.startedAt = time.Now()
+	w.finishedAt = time.Time{}
+	w.lastErr = nil
+	w.mu.Unlock()
+
+	err := w.run(ctx, rebuild)
+
+	w.mu.Lock()
+	w.finishedAt = time.Now()
+	w.lastErr = err
+	w.mu.Unlock()
+
+	progress := w.Progress()
+	if err != nil {
+		slog.Error("Cache warm-up stopped", "error", err,
+			"loaded", progress.Loaded, "failed", progress.Failed, "batches", progress.Batches)
+		return err
+	}
+
+	slog.Info("Cache warm-up completed",
+		"loaded", progress.Loaded,
+		"failed", progress.Failed,
+		"batches", progress.Batches,
+		"duration", progress.FinishedAt.Sub(progress.StartedAt))
+	return nil
+}
+
+func (w *CacheWarmer) run(ctx context.Context, rebuild bool) error {
+	query := models.OrderPageQuery{Limit: w.opts.BatchSize}
+	if w.opts.MaxAge > 0 {
+		query.Since = time.Now().Add(-w.opts.MaxAge)
+	}
+
+	slog.Info("Cache warm-up started",
+		"batch_size", w.opts.BatchSize,
+		"max_age", w.opts.MaxAge,
+		"max_orders", w.opts.MaxOrders,
+		"rebuild", rebuild)
+
+	seen := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if w.opts.MaxOrders > 0 {
+			remaining := w.opts.MaxOrders - seen
+			if remaining <= 0 {
+				return nil
+			}
+			if remaining < query.Limit {
+				query.Limit = remaining
+			}
+		}
+
+		page, err := w.repo.ListOrderSnapshots(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to load orders page: %w", err)
+		}
+		if len(page) == 0 {
+			return nil
+		}
+
+		w.loadBatch(ctx, page, rebuild)
+		seen += len(page)
+		w.batches.Add(1)
+
+		slog.Info("Cache warm-up progress",
+			"batch", w.batches.Load(),
+			"loaded", w.loaded.Load(),
+			"failed", w.failed.Load())
+
+		if len(page) < query.Limit {
+			return nil
+		}
+
+		last := page[len(page)-1]
+		query.AfterCreated = last.DateCreated
+		query.AfterUID = last.OrderUID
+	}
+}
+
+// loadBatch кладет страницу в кеш; заказы без валидного снимка догружаются из БД параллельно
+func (w *CacheWarmer) loadBatch(ctx context.Context, page []models.OrderSnapshot, rebuild bool) {
+	sem := make(chan struct{}, w.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, snapshot := range page {
+		if !rebuild && len(snapshot.Data) > 0 && json.Valid(snapshot.Data) {
+			w.cache.Set(snapshot.OrderUID, snapshot.Data, w.ttl.For(snapshot.DateCreated))
+			w.loaded.Add(1)
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(uid string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := w.loadOrder(ctx, uid); err != nil {
+				slog.Error("Failed to load order for cache", "error", err, "orderUID", uid)
+				w.failed.Add(1)
+				return
+			}
+			w.loaded.Add(1)
+		}(snapshot.OrderUID)
+	}
+
+	wg.Wait()
+}
+
+func (w *CacheWarmer) loadOrder(ctx context.Context, orderUID string) error {
+	orderCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	data, ttl, err := w.load(orderCtx, orderUID)
+	if err != nil {
+		return err
+	}
+
+	w.cache.Set(orderUID, data, ttl)
+	return nil
+}
+
+// Progress возвращает состояние текущего или последнего прогрева
+func (w *CacheWarmer) Progress() models.WarmupProgress {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	progress := models.WarmupProgress{
+		Running:    w.running.Load(),
+		Rebuild:    w.rebuild,
+		StartedAt:  w.startedAt,
+		FinishedAt: w.finishedAt,
+		Batches:    w.batches.Load(),
+		Loaded:     w.loaded.Load(),
+		Failed:     w.failed.Load(),
+	}
+	if w.lastErr != nil {
+		progress.LastError = w.lastErr.Error()
+	}
+
+	return progress
+}
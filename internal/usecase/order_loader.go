@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"order-service/pkg/interfaces"
+)
+
+// NewOrderCacheLoader возвращает загрузчик, который собирает заказ из таблиц БД,
+// обновляет его снимок в order_cache и выбирает TTL по политике
+func NewOrderCacheLoader(repo interfaces.OrderRepository, policy interfaces.TTLSource) interfaces.CacheLoader {
+	return func(ctx context.Context, orderUID string) ([]byte, time.Duration, error) {
+		order, err := repo.GetOrder(ctx, orderUID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		data, err := json.Marshal(order)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Сохраняем снимок, чтобы следующий прогрев обошелся без сборки заказа по таблицам
+		if err := repo.CacheOrderData(ctx, orderUID, data); err != nil {
+			slog.ErrorContext(ctx, "Failed to save order cache to DB", "error", err, "orderUID", orderUID)
+		}
+
+		return data, policy.For(order.DateCreated), nil
+	}
+}
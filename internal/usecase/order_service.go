@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/tracing"
+	"order-service/pkg/interfaces"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OrderService — сценарии работы с заказами, общие для транспортов: прием заказов из Kafka
+// и чтение по HTTP и gRPC. Чтение идет сначала из кеша, затем из БД.
+type OrderService struct {
+	repo      interfaces.OrderRepository
+	cache     interfaces.CacheRepository
+	ttlPolicy models.TTLPolicyVar
+	dbTimeout time.Duration
+
+	// Рассылка инвалидаций другим репликам и события о сохраненных заказах; nil — выключены
+	publisher interfaces.InvalidationPublisher
+	events    interfaces.OrderEventBus
+}
+
+func NewOrderService(repo interfaces.OrderRepository, cache interfaces.CacheRepository) *OrderService {
+	return &OrderService{
+		repo:      repo,
+		cache:     cache,
+		dbTimeout: 5 * time.Second,
+	}
+}
+
+// SetTTLPolicy задает политику TTL для заказов в кеше; безопасен во время работы
+func (s *OrderService) SetTTLPolicy(policy models.TTLPolicy) {
+	s.ttlPolicy.Store(policy)
+}
+
+// SetInvalidationPublisher включает рассылку инвалидаций при корректировке существующих заказов
+func (s *OrderService) SetInvalidationPublisher(publisher interfaces.InvalidationPublisher) {
+	s.publisher = publisher
+}
+
+// SetEventBus включает публикацию событий о сохраненных заказах
+func (s *OrderService) SetEventBus(events interfaces.OrderEventBus) {
+	s.events = events
+}
+
+// Ingest проверяет и сохраняет заказ вместе со снимком в БД, обновляет кеш, публикует событие.
+// Повторная доставка того же заказа ничего не меняет; измененный заказ — это корректировка.
+func (s *OrderService) Ingest(ctx context.Context, order models.Order) (models.IngestResult, error) {
+	if err := order.Validate(); err != nil {
+		return "", err
+	}
+
+	orderJSON, err := json.Marshal(order)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", models.ErrInvalidOrder, err)
+	}
+
+	// Пропускаем повторную доставку того же заказа
+	cacheSpan := tracing.CacheSpan(ctx, "get", order.OrderUID)
+	existing, found := s.cache.Get(order.OrderUID)
+	cacheSpan.SetAttributes(attribute.Bool("cache.hit", found))
+	cacheSpan.End()
+	if found && bytes.Equal(existing, orderJSON) {
+		return models.OrderIngestDuplicate, nil
+	}
+
+	result, err := s.repo.SaveOrder(ctx, order)
+	if err != nil {
+		return "", fmt.Errorf("failed to save order: %w", err)
+	}
+	updated := result == models.OrderIngestUpdated
+
+	// Другие реплики могут хранить прежнюю версию заказа
+	if updated {
+		s.publishInvalidation(ctx, order.OrderUID)
+	}
+
+	cacheSpan = tracing.CacheSpan(ctx, "set", order.OrderUID)
+	s.cache.Set(order.OrderUID, orderJSON, s.ttlPolicy.For(order.DateCreated))
+	cacheSpan.End()
+
+	// Заказ совпал с сохраненным в БД (кеш его уже не хранил): событий нет
+	if result == models.OrderIngestDuplicate {
+		return result, nil
+	}
+
+	eventType := models.OrderCreated
+	if updated {
+		eventType = models.OrderUpdated
+	}
+	if s.events != nil {
+		s.events.Publish(models.OrderEvent{Type: eventType, Order: order})
+	}
+	return result, nil
+}
+
+func (s *OrderService) publishInvalidation(ctx context.Context, orderUID string) {
+	if s.publisher == nil {
+		return
+	}
+
+	err := s.publisher.PublishInvalidation(ctx, models.CacheInvalidation{
+		Keys:   []string{orderUID},
+		Reason: "order_updated",
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish cache invalidation", "error", err)
+	}
+}
+
+// Get ищет заказ сначала в кеше, затем в БД, и кладет найденное в БД в кеш
+func (s *OrderService) Get(ctx context.Context, orderUID string) (*models.Order, error) {
+	if !models.ValidOrderUID(orderUID) {
+		return nil, models.ErrInvalidOrderUID
+	}
+
+	cacheSpan := tracing.CacheSpan(ctx, "get", orderUID)
+	cachedData, found := s.cache.Get(orderUID)
+	cacheSpan.SetAttributes(attribute.Bool("cache.hit", found))
+	cacheSpan.End()
+
+	if found {
+		var cachedOrder models.Order
+		if err := json.Unmarshal(cachedData, &cachedOrder); err == nil {
+			slog.InfoContext(ctx, "Order found in cache", "orderUID", orderUID)
+			return &cachedOrder, nil
+		} else {
+			slog.ErrorContext(ctx, "Failed to unmarshal cached order", "error", err)
+			// Продолжаем и попробуем получить из БД
+		}
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	order, err := s.repo.GetOrder(dbCtx, orderUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrOrderNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if orderJSON, err := json.Marshal(order); err == nil {
+		cacheSpan := tracing.CacheSpan(ctx, "set", orderUID)
+		s.cache.Set(orderUID, orderJSON, s.ttlPolicy.For(order.DateCreated))
+		cacheSpan.End()
+	} else {
+		slog.ErrorContext(ctx, "Failed to marshal order for caching", "error", err)
+	}
+
+	return order, nil
+}
+
+// GetMany возвращает заказы в порядке запроса: сначала из кеша, промахи — одним запросом к БД.
+// Повторы идентификаторов схлопываются, некорректные идентификаторы считаются ненайденными.
+func (s *OrderService) GetMany(ctx context.Context, orderUIDs []string) ([]models.Order, []string, error) {
+	if len(orderUIDs) > models.MaxBatchOrders {
+		return nil, nil, models.ErrBatchTooLarge
+	}
+
+	found := make(map[string]models.Order, len(orderUIDs))
+	var misses []string
+	seen := make(map[string]bool, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if seen[orderUID] || !models.ValidOrderUID(orderUID) {
+			continue
+		}
+		seen[orderUID] = true
+
+		cacheSpan := tracing.CacheSpan(ctx, "get", orderUID)
+		cachedData, ok := s.cache.Get(orderUID)
+		cacheSpan.SetAttributes(attribute.Bool("cache.hit", ok))
+		cacheSpan.End()
+
+		if ok {
+			var order models.Order
+			if err := json.Unmarshal(cachedData, &order); err == nil {
+				found[orderUID] = order
+				continue
+			}
+			slog.ErrorContext(ctx, "Failed to unmarshal cached order", "orderUID", orderUID)
+		}
+		misses = append(misses, orderUID)
+	}
+
+	if len(misses) > 0 {
+		dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		defer cancel()
+
+		loaded, err := s.repo.GetOrders(dbCtx, misses)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, order := range loaded {
+			found[order.OrderUID] = order
+			if orderJSON, err := json.Marshal(order); err == nil {
+				cacheSpan := tracing.CacheSpan(ctx, "set", order.OrderUID)
+				s.cache.Set(order.OrderUID, orderJSON, s.ttlPolicy.For(order.DateCreated))
+				cacheSpan.End()
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "Orders batch lookup",
+		"requested", len(orderUIDs),
+		"cache_hits", len(seen)-len(misses),
+		"db_lookups", len(misses))
+
+	orders := make([]models.Order, 0, len(found))
+	var missing []string
+	reported := make(map[string]bool, len(orderUIDs))
+	for _, orderUID := range orderUIDs {
+		if reported[orderUID] {
+			continue
+		}
+		reported[orderUID] = true
+
+		if order, ok := found[orderUID]; ok {
+			orders = append(orders, order)
+		} else {
+			missing = append(missing, orderUID)
+		}
+	}
+
+	return orders, missing, nil
+}
+
+// List возвращает страницу заказов от новых к старым.
+// Заказы берутся из снимков order_cache, а без снимка — через Get.
+func (s *OrderService) List(ctx context.Context, query models.OrderPageQuery) (models.OrderPage, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	snapshots, err := s.repo.ListOrderSnapshots(dbCtx, query)
+	if err != nil {
+		return models.OrderPage{}, err
+	}
+
+	orders := make([]models.Order, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if len(snapshot.Data) > 0 {
+			var order models.Order
+			if err := json.Unmarshal(snapshot.Data, &order); err == nil {
+				orders = append(orders, order)
+				continue
+			}
+			slog.WarnContext(ctx, "Invalid order snapshot, loading from tables", "orderUID", snapshot.OrderUID)
+		}
+
+		order, err := s.Get(ctx, snapshot.OrderUID)
+		if errors.Is(err, models.ErrOrderNotFound) || errors.Is(err, models.ErrInvalidOrderUID) {
+			// Заказ удален между запросами — пропускаем
+			continue
+		} else if err != nil {
+			return models.OrderPage{}, err
+		}
+		orders = append(orders, *order)
+	}
+
+	page := models.OrderPage{Orders: orders}
+	// Полная выборка — возможно, есть следующая страница
+	if query.Limit > 0 && len(snapshots) == query.Limit {
+		last := snapshots[len(snapshots)-1]
+		page.Next = &models.OrderPageCursor{Created: last.DateCreated, UID: last.OrderUID}
+	}
+	return page, nil
+}
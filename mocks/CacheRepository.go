@@ -1,16 +1,22 @@
 // mocks/CacheRepository.go
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	"time"
+
+	"order-service/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // CacheRepository is an autogenerated mock type for the CacheRepository type
 type CacheRepository struct {
 	mock.Mock
 }
 
-// Set provides a mock function with given fields: key, data
-func (_m *CacheRepository) Set(key string, data []byte) {
-	_m.Called(key, data)
+// Set provides a mock function with given fields: key, data, ttl
+func (_m *CacheRepository) Set(key string, data []byte, ttl time.Duration) {
+	_m.Called(key, data, ttl)
 }
 
 // Get provides a mock function with given fields: key
@@ -55,7 +61,56 @@ func (_m *CacheRepository) Delete(key string) {
 	_m.Called(key)
 }
 
-// PrintContent provides a mock function with given fields:
-func (_m *CacheRepository) PrintContent() {
+// Stats provides a mock function with given fields:
+func (_m *CacheRepository) Stats() models.CacheStats {
+	ret := _m.Called()
+
+	var r0 models.CacheStats
+	if rf, ok := ret.Get(0).(func() models.CacheStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(models.CacheStats)
+	}
+
+	return r0
+}
+
+// DeletePrefix provides a mock function with given fields: prefix
+func (_m *CacheRepository) DeletePrefix(prefix string) int {
+	ret := _m.Called(prefix)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(prefix)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// Flush provides a mock function with given fields:
+func (_m *CacheRepository) Flush() {
 	_m.Called()
 }
+
+// Inspect provides a mock function with given fields: key
+func (_m *CacheRepository) Inspect(key string) (models.CacheEntryInfo, bool) {
+	ret := _m.Called(key)
+
+	var r0 models.CacheEntryInfo
+	if rf, ok := ret.Get(0).(func(string) models.CacheEntryInfo); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(models.CacheEntryInfo)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
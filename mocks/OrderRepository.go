@@ -14,22 +14,22 @@ type OrderRepository struct {
 	mock.Mock
 }
 
-// GetAllOrders provides a mock function with given fields:
-func (_m *OrderRepository) GetAllOrders() ([]string, error) {
-	ret := _m.Called()
+// GetOrder provides a mock function with given fields: ctx, orderUID
+func (_m *OrderRepository) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
+	ret := _m.Called(ctx, orderUID)
 
-	var r0 []string
-	if rf, ok := ret.Get(0).(func() []string); ok {
-		r0 = rf()
+	var r0 *models.Order
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Order); ok {
+		r0 = rf(ctx, orderUID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]string)
+			r0 = ret.Get(0).(*models.Order)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, orderUID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -37,22 +37,22 @@ func (_m *OrderRepository) GetAllOrders() ([]string, error) {
 	return r0, r1
 }
 
-// GetOrder provides a mock function with given fields: ctx, orderUID
-func (_m *OrderRepository) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
-	ret := _m.Called(ctx, orderUID)
+// GetOrders provides a mock function with given fields: ctx, orderUIDs
+func (_m *OrderRepository) GetOrders(ctx context.Context, orderUIDs []string) ([]models.Order, error) {
+	ret := _m.Called(ctx, orderUIDs)
 
-	var r0 *models.Order
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Order); ok {
-		r0 = rf(ctx, orderUID)
+	var r0 []models.Order
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []models.Order); ok {
+		r0 = rf(ctx, orderUIDs)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Order)
+			r0 = ret.Get(0).([]models.Order)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, orderUID)
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, orderUIDs)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -61,26 +61,33 @@ func (_m *OrderRepository) GetOrder(ctx context.Context, orderUID string) (*mode
 }
 
 // SaveOrder provides a mock function with given fields: ctx, order
-func (_m *OrderRepository) SaveOrder(ctx context.Context, order models.Order) error {
+func (_m *OrderRepository) SaveOrder(ctx context.Context, order models.Order) (models.IngestResult, error) {
 	ret := _m.Called(ctx, order)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.Order) error); ok {
+	var r0 models.IngestResult
+	if rf, ok := ret.Get(0).(func(context.Context, models.Order) models.IngestResult); ok {
 		r0 = rf(ctx, order)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(models.IngestResult)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.Order) error); ok {
+		r1 = rf(ctx, order)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// CacheOrderData provides a mock function with given fields: orderUID, orderData
-func (_m *OrderRepository) CacheOrderData(orderUID string, orderData []byte) error {
-	ret := _m.Called(orderUID, orderData)
+// CacheOrderData provides a mock function with given fields: ctx, orderUID, orderData
+func (_m *OrderRepository) CacheOrderData(ctx context.Context, orderUID string, orderData []byte) error {
+	ret := _m.Called(ctx, orderUID, orderData)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
-		r0 = rf(orderUID, orderData)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) error); ok {
+		r0 = rf(ctx, orderUID, orderData)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -110,3 +117,26 @@ func (_m *OrderRepository) GetCachedOrderData(orderUID string) ([]byte, error) {
 
 	return r0, r1
 }
+
+// ListOrderSnapshots provides a mock function with given fields: ctx, query
+func (_m *OrderRepository) ListOrderSnapshots(ctx context.Context, query models.OrderPageQuery) ([]models.OrderSnapshot, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []models.OrderSnapshot
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderPageQuery) []models.OrderSnapshot); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OrderSnapshot)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, models.OrderPageQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -4,34 +4,107 @@ package interfaces
 import (
 	"context"
 	"order-service/internal/domain/models"
+	"time"
 )
 
+// CacheLoader загружает актуальные данные ключа из первоисточника и возвращает TTL для них
+type CacheLoader func(ctx context.Context, key string) ([]byte, time.Duration, error)
+
+// TTLSource выбирает TTL по дате создания заказа
+type TTLSource interface {
+	For(dateCreated time.Time) time.Duration
+}
+
 // CacheRepository представляет интерфейс для кеширования
 type CacheRepository interface {
-	Set(key string, data []byte)
+	// Set сохраняет данные; ttl <= 0 означает TTL бэкенда по умолчанию
+	Set(key string, data []byte, ttl time.Duration)
 	Get(key string) ([]byte, bool)
 	Has(key string) bool
 	Delete(key string)
-	PrintContent()
+	DeletePrefix(prefix string) int
+	Flush()
+	Inspect(key string) (models.CacheEntryInfo, bool)
+	Stats() models.CacheStats
+}
+
+// CacheWarmer представляет интерфейс для прогрева кеша из БД
+type CacheWarmer interface {
+	// TryStart атомарно запускает прогрев в фоне или возвращает models.ErrWarmupInProgress;
+	// канал получает результат прогрева
+	TryStart(ctx context.Context, rebuild bool) (<-chan error, error)
+	Progress() models.WarmupProgress
 }
 
 // OrderRepository представляет интерфейс для работы с заказами в БД
 type OrderRepository interface {
-	SaveOrder(ctx context.Context, order models.Order) error
+	// SaveOrder сохраняет заказ и его снимок атомарно. Возвращает models.OrderIngestUpdated, только если
+	// содержимое существующего заказа изменилось, и models.OrderIngestDuplicate, если заказ совпадает с сохраненным
+	SaveOrder(ctx context.Context, order models.Order) (models.IngestResult, error)
 	GetOrder(ctx context.Context, orderUID string) (*models.Order, error)
-	GetAllOrders() ([]string, error)
-	CacheOrderData(orderUID string, orderData []byte) error
+	// GetOrders загружает несколько заказов одним запросом; отсутствующие в результат не попадают
+	GetOrders(ctx context.Context, orderUIDs []string) ([]models.Order, error)
+	ListOrderSnapshots(ctx context.Context, query models.OrderPageQuery) ([]models.OrderSnapshot, error)
+	CacheOrderData(ctx context.Context, orderUID string, orderData []byte) error
 	GetCachedOrderData(orderUID string) ([]byte, error)
 }
 
+// OrderService — сценарии работы с заказами для транспортов: прием из Kafka, чтение по HTTP и gRPC
+type OrderService interface {
+	// Ingest проверяет и сохраняет заказ. Для некорректного заказа возвращает ошибку с models.ErrInvalidOrder.
+	// Заказ, совпадающий с сохраненным (в кеше или в БД), возвращает models.OrderIngestDuplicate;
+	// models.OrderIngestUpdated — только если содержимое существующего заказа изменилось.
+	Ingest(ctx context.Context, order models.Order) (models.IngestResult, error)
+	// Get возвращает models.ErrInvalidOrderUID или models.ErrOrderNotFound для некорректных и отсутствующих заказов
+	Get(ctx context.Context, orderUID string) (*models.Order, error)
+	// GetMany возвращает найденные заказы в порядке запроса и идентификаторы ненайденных
+	GetMany(ctx context.Context, orderUIDs []string) (orders []models.Order, missing []string, err error)
+	// List возвращает страницу заказов от новых к старым; страница может быть короче query.Limit,
+	// если часть заказов не удалось загрузить, поэтому конец выборки определяет только page.Next
+	List(ctx context.Context, query models.OrderPageQuery) (models.OrderPage, error)
+}
+
+// OrderEventBus представляет внутрипроцессную шину событий о заказах
+type OrderEventBus interface {
+	Publish(event models.OrderEvent)
+	// Subscribe возвращает канал событий, прошедших фильтр; при afterID > 0 сначала отдаются
+	// сохраненные события после него. Канал закрывается при отписке или если подписчик не успевает читать.
+	Subscribe(filter models.OrderEventFilter, afterID uint64, buffer int) (events <-chan models.OrderEvent, unsubscribe func())
+}
+
+// ConfigReloader перечитывает конфигурацию и применяет параметры, изменяемые без перезапуска
+type ConfigReloader interface {
+	Reload(trigger string) models.ConfigReload
+	// LastReload возвращает результат последней перезагрузки; false, если ее еще не было
+	LastReload() (models.ConfigReload, bool)
+}
+
+// InvalidationPublisher рассылает инвалидации кеша остальным репликам сервиса
+type InvalidationPublisher interface {
+	PublishInvalidation(ctx context.Context, invalidation models.CacheInvalidation) error
+}
+
+// Authenticator проверяет учетные данные запроса. Если данных подходящего вида нет,
+// возвращает models.ErrNoCredentials, если они не прошли проверку — models.ErrInvalidCredentials.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials models.Credentials) (*models.Principal, error)
+}
+
 // KafkaConsumer представляет интерфейс для работы с Kafka
 type KafkaConsumer interface {
 	Start(ctx context.Context) error
 	Shutdown(ctx context.Context) error
 }
 
-// HTTPServer представляет интерфейс для HTTP-сервера
+// HTTPServer представляет интерфейс для HTTP-сервера (используется и для gRPC)
 type HTTPServer interface {
 	Start() error
 	Shutdown(ctx context.Context) error
 }
+
+// FailureReporter — компонент, который может отказать уже после успешного запуска.
+// Failures возвращает канал текущего запуска: ошибка в нем означает, что компонент больше не работает
+// и его нужно остановить, а затем при необходимости запустить снова.
+type FailureReporter interface {
+	Failures() <-chan error
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+const testAdminToken = "secret-token"
+
+func newAdminTestServer(t *testing.T) (*cache.Cache, *mocks.CacheWarmer, http.Handler) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockWarmer := new(mocks.CacheWarmer)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	server.EnableAdmin(mockWarmer)
+
+	return cacheRepo, mockWarmer, server.Handler()
+}
+
+func adminRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	return req
+}
+
+func TestAdmin_RequiresToken(t *testing.T) {
+	_, _, handler := newAdminTestServer(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/flush", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAdmin_StatsAndInspect(t *testing.T) {
+	cacheRepo, mockWarmer, handler := newAdminTestServer(t)
+	mockWarmer.On("Progress").Return(models.WarmupProgress{Loaded: 3})
+
+	cacheRepo.Set("order-1", []byte(`{"order_uid":"order-1"}`), 0)
+	cacheRepo.Get("order-1")
+	cacheRepo.Get("missing")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/cache/stats"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stats struct {
+		Cache  models.CacheStats     `json:"cache"`
+		Warmup models.WarmupProgress `json:"warmup"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, int64(1), stats.Cache.Entries)
+	assert.Equal(t, 0.5, stats.Cache.HitRatio)
+	assert.False(t, stats.Cache.OldestEntry.IsZero())
+	assert.Equal(t, int64(3), stats.Warmup.Loaded)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/cache/keys/order-1"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"key":"order-1"`)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/cache/keys/missing"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdmin_EvictAndFlush(t *testing.T) {
+	cacheRepo, _, handler := newAdminTestServer(t)
+
+	cacheRepo.Set("order-1", []byte(`{}`), 0)
+	cacheRepo.Set("test-1", []byte(`{}`), 0)
+	cacheRepo.Set("test-2", []byte(`{}`), 0)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodDelete, "/admin/cache/keys/order-1"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, cacheRepo.Has("order-1"))
+
+	// Удаление по префиксу требует явного префикса
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodDelete, "/admin/cache/keys"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodDelete, "/admin/cache/keys?prefix=test-"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"prefix":"test-","evicted":2}`, rec.Body.String())
+
+	cacheRepo.Set("order-2", []byte(`{}`), 0)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/cache/flush"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(0), cacheRepo.Stats().Entries)
+}
+
+func TestAdmin_Warmup(t *testing.T) {
+	_, mockWarmer, handler := newAdminTestServer(t)
+
+	done := make(chan error, 1)
+	done <- nil
+	mockWarmer.On("TryStart", mock.Anything, true).Return((<-chan error)(done), nil).Once()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/cache/warmup?rebuild=true"))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	// Повторный запуск во время прогрева отклоняется
+	mockWarmer.On("TryStart", mock.Anything, false).Return(nil, models.ErrWarmupInProgress).Once()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/cache/warmup"))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	mockWarmer.AssertExpectations(t)
+}
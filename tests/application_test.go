@@ -2,21 +2,64 @@ package tests
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
-	"order-service/internal/config"
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	orderkafka "order-service/internal/infrastructure/kafka"
 	appuse "order-service/internal/usecase"
 	"order-service/mocks"
 )
 
+// lifecycleLog записывает вызовы хуков компонентов
+type lifecycleLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *lifecycleLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *lifecycleLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+// component создает компонент, записывающий свои запуски и остановки
+func (l *lifecycleLog) component(name string, startErr error, dependsOn ...string) appuse.Component {
+	return appuse.Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(ctx context.Context) error {
+			l.add("start " + name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			l.add("stop " + name)
+			return nil
+		},
+	}
+}
+
 // Тест для метода Start приложения
 func TestApplication_Start(t *testing.T) {
 	// Создаем моки для интерфейсов
-	mockRepo := new(mocks.OrderRepository)
-	mockCache := new(mocks.CacheRepository)
 	mockKafka := new(mocks.KafkaConsumer)
 	mockHTTP := new(mocks.HTTPServer)
 
@@ -25,12 +68,10 @@ func TestApplication_Start(t *testing.T) {
 	mockHTTP.On("Start").Return(nil)
 
 	// Создаем экземпляр приложения с моками
-	app := appuse.NewApplication(
-		&config.Config{},
-		mockRepo,
-		mockCache,
-		mockKafka,
-		mockHTTP,
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		appuse.ServerComponent("http", mockHTTP, "kafka"),
+		appuse.ConsumerComponent("kafka", mockKafka),
 	)
 
 	// Тестируем метод Start
@@ -45,23 +86,22 @@ func TestApplication_Start(t *testing.T) {
 
 func TestApplication_Shutdown(t *testing.T) {
 	// Создаем моки для интерфейсов
-	mockRepo := new(mocks.OrderRepository)
-	mockCache := new(mocks.CacheRepository)
 	mockKafka := new(mocks.KafkaConsumer)
 	mockHTTP := new(mocks.HTTPServer)
 
 	// Настраиваем ожидания
+	mockKafka.On("Start", mock.Anything).Return(nil)
+	mockHTTP.On("Start").Return(nil)
 	mockKafka.On("Shutdown", mock.Anything).Return(nil)
 	mockHTTP.On("Shutdown", mock.Anything).Return(nil)
 
 	// Создаем экземпляр приложения с моками
-	app := appuse.NewApplication(
-		&config.Config{},
-		mockRepo,
-		mockCache,
-		mockKafka,
-		mockHTTP,
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		appuse.ConsumerComponent("kafka", mockKafka),
+		appuse.ServerComponent("http", mockHTTP),
 	)
+	require.NoError(t, app.Start(context.Background()))
 
 	// Тестируем метод Shutdown
 	ctx := context.Background()
@@ -72,3 +112,348 @@ func TestApplication_Shutdown(t *testing.T) {
 	mockKafka.AssertExpectations(t)
 	mockHTTP.AssertExpectations(t)
 }
+
+func TestApplication_DependencyOrder(t *testing.T) {
+	var log lifecycleLog
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		log.component("http", nil, "postgres", "cache"),
+		log.component("consumer", nil, "postgres"),
+		log.component("cache", nil),
+		log.component("postgres", nil),
+	)
+
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{
+		"start postgres", "start cache", "start http", "start consumer",
+		"stop consumer", "stop http", "stop cache", "stop postgres",
+	}, log.get())
+}
+
+func TestApplication_StartFailureRollsBack(t *testing.T) {
+	var log lifecycleLog
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		log.component("postgres", nil),
+		log.component("cache", nil),
+		log.component("consumer", errors.New("broker unavailable"), "postgres"),
+		log.component("http", nil, "consumer"),
+	)
+
+	err := app.Start(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "failed to start consumer: broker unavailable", err.Error())
+
+	// Запущенные компоненты останавливаются в обратном порядке, последующие не запускаются
+	assert.Equal(t, []string{
+		"start postgres", "start cache", "start consumer",
+		"stop cache", "stop postgres",
+	}, log.get())
+	assert.NoError(t, app.Shutdown(context.Background()))
+}
+
+func TestApplication_StartInterrupted(t *testing.T) {
+	var log lifecycleLog
+	ctx, cancel := context.WithCancel(context.Background())
+
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		log.component("cache", nil),
+		// Компонент ждет готовности зависимости, пока запуск не прерван
+		appuse.Component{
+			Name: "postgres",
+			Start: func(ctx context.Context) error {
+				cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		log.component("http", nil, "postgres"),
+	)
+
+	err := app.Start(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"start cache", "stop cache"}, log.get())
+}
+
+func TestApplication_ShutdownTimeouts(t *testing.T) {
+	var log lifecycleLog
+	block := make(chan struct{})
+	defer close(block)
+
+	var jobCtx context.Context
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		log.component("postgres", nil),
+		appuse.Component{
+			Name:        "stuck",
+			DependsOn:   []string{"postgres"},
+			Stop:        func(ctx context.Context) error { <-block; return nil },
+			StopTimeout: 20 * time.Millisecond,
+		},
+		appuse.BackgroundJob("job", func(ctx context.Context) error {
+			jobCtx = ctx
+			<-ctx.Done()
+			return ctx.Err()
+		}, "stuck"),
+	)
+	require.NoError(t, app.Start(context.Background()))
+
+	start := time.Now()
+	err := app.Shutdown(context.Background())
+
+	// Зависший компонент не мешает остановить остальные
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stuck: did not stop in time")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, []string{"start postgres", "stop postgres"}, log.get())
+	require.NotNil(t, jobCtx)
+	assert.Error(t, jobCtx.Err())
+}
+
+func TestApplication_InvalidDependencies(t *testing.T) {
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		appuse.Component{Name: "a", DependsOn: []string{"b"}},
+		appuse.Component{Name: "b", DependsOn: []string{"a"}},
+	)
+	err := app.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle: a -> b -> a")
+
+	app = appuse.NewApplication(time.Second)
+	app.Add(appuse.Component{Name: "http", DependsOn: []string{"postgres"}})
+	err = app.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "depends on unknown component postgres")
+}
+
+// failingComponent отказывает после каждого запуска, пока не исчерпан запас отказов
+func failingComponent(log *lifecycleLog, failures int) appuse.Component {
+	var current chan error
+	return appuse.Component{
+		Name: "consumer",
+		Start: func(ctx context.Context) error {
+			log.add("start consumer")
+			current = make(chan error, 1)
+			if failures > 0 {
+				failures--
+				current <- errors.New("reader closed")
+			}
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			log.add("stop consumer")
+			return nil
+		},
+		Failures: func() <-chan error { return current },
+		Restart:  appuse.RestartPolicy{MaxRestarts: 2, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond},
+	}
+}
+
+func TestApplication_RestartsFailedComponent(t *testing.T) {
+	var log lifecycleLog
+	app := appuse.NewApplication(time.Second)
+	app.Add(failingComponent(&log, 2))
+	require.NoError(t, app.Start(context.Background()))
+
+	// Два отказа укладываются в MaxRestarts: после второго перезапуска компонент работает
+	assert.Eventually(t, func() bool { return len(log.get()) == 5 }, time.Second, time.Millisecond)
+	select {
+	case err := <-app.Failed():
+		t.Fatalf("unexpected failure: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	assert.Equal(t, []string{
+		"start consumer", "stop consumer", "start consumer", "stop consumer", "start consumer", "stop consumer",
+	}, log.get())
+}
+
+func TestApplication_FailsWhenRestartsExhausted(t *testing.T) {
+	var log lifecycleLog
+	app := appuse.NewApplication(time.Second)
+	app.Add(
+		log.component("postgres", nil),
+		failingComponent(&log, 3),
+		appuse.BackgroundJob("job", func(ctx context.Context) error { <-ctx.Done(); return nil }),
+	)
+	require.NoError(t, app.Start(context.Background()))
+
+	select {
+	case err := <-app.Failed():
+		assert.Equal(t, "consumer: reader closed", err.Error())
+	case <-time.After(time.Second):
+		t.Fatal("application did not report the failure")
+	}
+
+	// Компонент, отказавший окончательно, освобождает ресурсы при остановке приложения
+	require.NoError(t, app.Shutdown(context.Background()))
+	assert.Equal(t, []string{
+		"start postgres", "start consumer", "stop consumer", "start consumer", "stop consumer", "start consumer",
+		"stop consumer", "stop postgres",
+	}, log.get())
+}
+
+func TestApplication_BackgroundJobFailure(t *testing.T) {
+	app := appuse.NewApplication(time.Second)
+	app.Add(appuse.BackgroundJob("watcher", func(ctx context.Context) error {
+		panic("unexpected state")
+	}))
+	require.NoError(t, app.Start(context.Background()))
+
+	select {
+	case err := <-app.Failed():
+		assert.Equal(t, "watcher: panic: unexpected state", err.Error())
+	case <-time.After(time.Second):
+		t.Fatal("application did not report the failure")
+	}
+	assert.NoError(t, app.Shutdown(context.Background()))
+}
+
+func TestApplication_HTTPPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	cacheRepo := cache.NewCache(time.Minute)
+	server := orderhttp.NewOrderHTTPServer(port, appuse.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+
+	// Занятый порт — ошибка запуска, а не запись в журнале из фоновой горутины
+	app := appuse.NewApplication(time.Second)
+	app.Add(appuse.ServerComponent("http", server))
+	err = app.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start http: failed to listen on")
+}
+
+// fakeReader отдает сообщения из messages, пока его не закроют; Close возвращает closeErr
+type fakeReader struct {
+	closeErr  error
+	messages  chan kafka.Message
+	closed    chan struct{}
+	fetching  chan struct{}
+	once      sync.Once
+	closeOnce sync.Once
+	commits   atomic.Int32
+}
+
+func newFakeReader(closeErr error) *fakeReader {
+	return &fakeReader{
+		closeErr: closeErr,
+		messages: make(chan kafka.Message, 1),
+		closed:   make(chan struct{}),
+		fetching: make(chan struct{}),
+	}
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.once.Do(func() { close(r.fetching) })
+	select {
+	case <-r.closed:
+		return kafka.Message{}, io.EOF
+	case msg := <-r.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(context.Context, ...kafka.Message) error {
+	r.commits.Add(1)
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return r.closeErr
+}
+
+func TestApplication_ConsumerRestartsAfterFailedClose(t *testing.T) {
+	readers := make(chan *fakeReader, 2)
+	closeErrs := []error{errors.New("close failed"), nil}
+
+	consumer := orderkafka.NewOrderKafkaConsumer(nil, "orders", "group", new(mocks.OrderService))
+	consumer.SetReaderFactory(func(kafka.ReaderConfig) orderkafka.MessageReader {
+		reader := newFakeReader(closeErrs[0])
+		closeErrs = closeErrs[1:]
+		readers <- reader
+		return reader
+	})
+
+	// nextReader дожидается, пока запущенный потребитель начнет читать из нового reader
+	nextReader := func() *fakeReader {
+		t.Helper()
+		select {
+		case reader := <-readers:
+			select {
+			case <-reader.fetching:
+			case <-time.After(time.Second):
+				t.Fatal("consumer does not read messages")
+			}
+			return reader
+		case <-time.After(time.Second):
+			t.Fatal("consumer did not create a reader")
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	require.NoError(t, consumer.Start(ctx))
+	nextReader()
+	assert.ErrorContains(t, consumer.Shutdown(ctx), "close failed")
+
+	// Перезапуск после неудачной остановки действительно читает сообщения
+	require.NoError(t, consumer.Start(ctx))
+	nextReader()
+	assert.NoError(t, consumer.Shutdown(ctx))
+}
+
+func TestConsumer_RestartWaitsForUnfinishedReadLoop(t *testing.T) {
+	ingesting := make(chan struct{})
+	release := make(chan struct{})
+	orders := new(mocks.OrderService)
+	orders.On("Ingest", mock.Anything, mock.Anything).
+		Return(models.OrderIngestCreated, nil).
+		Run(func(mock.Arguments) {
+			close(ingesting)
+			<-release
+		}).Once()
+
+	first := newFakeReader(nil)
+	first.messages <- kafka.Message{Value: []byte(`{"order_uid":"order-1"}`)}
+	second := newFakeReader(nil)
+	readers := []*fakeReader{first, second}
+
+	consumer := orderkafka.NewOrderKafkaConsumer(nil, "orders", "group", orders)
+	consumer.SetReaderFactory(func(kafka.ReaderConfig) orderkafka.MessageReader {
+		reader := readers[0]
+		readers = readers[1:]
+		return reader
+	})
+
+	require.NoError(t, consumer.Start(context.Background()))
+	<-ingesting
+
+	// Остановка не дожидается обработки сообщения
+	stopCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, consumer.Shutdown(stopCtx), context.DeadlineExceeded)
+
+	// Пока прежняя горутина работает, второй запуск отклоняется
+	assert.Error(t, consumer.Start(context.Background()))
+
+	// Сообщение подтверждается через reader, из которого оно прочитано
+	close(release)
+	assert.Eventually(t, func() bool { return first.commits.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	assert.Eventually(t, func() bool { return consumer.Start(context.Background()) == nil }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, consumer.Shutdown(context.Background()))
+	assert.Equal(t, int32(0), second.commits.Load())
+	orders.AssertExpectations(t)
+}
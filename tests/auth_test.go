@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/auth"
+	"order-service/internal/infrastructure/cache"
+	ordergrpc "order-service/internal/infrastructure/grpc"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+	orderv1 "order-service/pkg/api/order/v1"
+	"order-service/pkg/interfaces"
+)
+
+const (
+	testSupportKey = "support-key"
+	testServiceKey = "service-key"
+)
+
+func newTestAuthenticator(t *testing.T) *auth.APIKeyAuthenticator {
+	t.Helper()
+
+	authenticator, err := auth.NewAPIKeyAuthenticator([]auth.APIKey{
+		{Name: "admin", Key: testAdminToken, Roles: []models.Role{models.RoleAdmin}},
+		{Name: "support-ui", Key: testSupportKey, Roles: []models.Role{models.RoleSupport}},
+		{Name: "billing", Key: testServiceKey, Roles: []models.Role{models.RoleService}},
+	})
+	require.NoError(t, err)
+	return authenticator
+}
+
+func newAuthTestServer(t *testing.T, authenticator interfaces.Authenticator, routeRoles map[string][]models.Role) http.Handler {
+	cacheRepo := cache.NewCache(time.Minute)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order"}`), 0)
+
+	mockWarmer := new(mocks.CacheWarmer)
+	mockWarmer.On("Progress").Return(models.WarmupProgress{}).Maybe()
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(authenticator)
+	server.SetRouteRoles(routeRoles)
+	server.EnableAdmin(mockWarmer)
+	return server.Handler()
+}
+
+func TestAuth_APIKeys(t *testing.T) {
+	handler := newAuthTestServer(t, newTestAuthenticator(t), map[string][]models.Role{
+		"GET /orders/{uid}": {models.RoleSupport},
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		value  string
+		status int
+	}{
+		{"no credentials", "/api/v1/orders/test-order", "", "", http.StatusUnauthorized},
+		{"unknown key", "/api/v1/orders/test-order", "X-API-Key", "wrong", http.StatusUnauthorized},
+		{"support via header", "/api/v1/orders/test-order", "X-API-Key", testSupportKey, http.StatusOK},
+		{"service via bearer", "/api/v1/orders/test-order", "Authorization", "Bearer " + testServiceKey, http.StatusOK},
+		{"route override", "/orders/test-order", "X-API-Key", testServiceKey, http.StatusForbidden},
+		{"support on admin", "/admin/cache/stats", "X-API-Key", testSupportKey, http.StatusForbidden},
+		{"public route", "/health", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, "unauthorized", decodeProblem(t, rec).Code)
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			} else if tt.status == http.StatusForbidden {
+				assert.Equal(t, "forbidden", decodeProblem(t, rec).Code)
+			}
+		})
+	}
+}
+
+func TestAuth_ParseAPIKeys(t *testing.T) {
+	keys, err := auth.ParseAPIKeys("support-ui:support:k1, ops:admin|service:k2")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, []models.Role{models.RoleAdmin, models.RoleService}, keys[1].Roles)
+
+	_, err = auth.ParseAPIKeys("ops:root:k3")
+	assert.Error(t, err)
+}
+
+// newTestJWKS создает ключ Ed25519 и JWKS с его открытой частью
+func newTestJWKS(t *testing.T) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"test-key","use":"sig","x":%q}]}`,
+		base64.RawURLEncoding.EncodeToString(public))
+	return private, []byte(jwks)
+}
+
+func TestAuth_JWKSKeyIDs(t *testing.T) {
+	first, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	second, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	jwk := func(kid string, public ed25519.PublicKey) string {
+		return fmt.Sprintf(`{"kty":"OKP","crv":"Ed25519","kid":%q,"x":%q}`, kid, base64.RawURLEncoding.EncodeToString(public))
+	}
+
+	// Единственный ключ может быть без kid
+	keys, err := auth.ParseJWKS([]byte(`{"keys":[` + jwk("", first) + `]}`))
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	// Ключи без kid перезаписали бы друг друга
+	_, err = auth.ParseJWKS([]byte(`{"keys":[` + jwk("", first) + `,` + jwk("", second) + `]}`))
+	assert.ErrorContains(t, err, "kid is required")
+	_, err = auth.ParseJWKS([]byte(`{"keys":[` + jwk("a", first) + `,` + jwk("a", second) + `]}`))
+	assert.ErrorContains(t, err, "duplicate kid")
+
+	keys, err = auth.ParseJWKS([]byte(`{"keys":[` + jwk("a", first) + `,` + jwk("b", second) + `]}`))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestAuth_JWT(t *testing.T) {
+	private, jwks := newTestJWKS(t)
+	keys, err := auth.ParseJWKS(jwks)
+	require.NoError(t, err)
+
+	jwtAuth, err := auth.NewJWTAuthenticator(keys, auth.JWTOptions{Issuer: "https://sso.local", Audience: "order-service"})
+	require.NoError(t, err)
+	handler := newAuthTestServer(t, auth.Chain{newTestAuthenticator(t), jwtAuth}, nil)
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "test-key"
+		signed, err := token.SignedString(private)
+		require.NoError(t, err)
+		return signed
+	}
+	claims := func(roles []string, expiresIn time.Duration, issuer string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "operator@sso",
+			"iss":   issuer,
+			"aud":   "order-service",
+			"exp":   time.Now().Add(expiresIn).Unix(),
+			"roles": roles,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		token  string
+		status int
+	}{
+		{"support reads order", "/api/v1/orders/test-order", sign(claims([]string{"support"}, time.Hour, "https://sso.local")), http.StatusOK},
+		{"admin route with admin", "/admin/cache/stats", sign(claims([]string{"support", "admin"}, time.Hour, "https://sso.local")), http.StatusOK},
+		{"no known roles", "/api/v1/orders/test-order", sign(claims([]string{"viewer"}, time.Hour, "https://sso.local")), http.StatusForbidden},
+		{"expired", "/api/v1/orders/test-order", sign(claims([]string{"support"}, -time.Hour, "https://sso.local")), http.StatusUnauthorized},
+		{"wrong issuer", "/api/v1/orders/test-order", sign(claims([]string{"support"}, time.Hour, "https://evil.local")), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAuth_GRPC(t *testing.T) {
+	mockOrders := new(mocks.OrderService)
+	mockOrders.On("Get", mock.Anything, "test-order").Return(&models.Order{OrderUID: "test-order"}, nil)
+
+	server := ordergrpc.NewOrderGRPCServer(0, mockOrders, nil)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	client := orderv1.NewOrderServiceClient(serveGRPCTest(t, server))
+
+	_, err := client.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: "test-order"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "wrong")
+	_, err = client.GetOrder(ctx, &orderv1.GetOrderRequest{OrderUid: "test-order"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+testServiceKey)
+	resp, err := client.GetOrder(ctx, &orderv1.GetOrderRequest{OrderUid: "test-order"})
+	require.NoError(t, err)
+	assert.Equal(t, "test-order", resp.GetOrder().GetOrderUid())
+}
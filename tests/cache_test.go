@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+)
+
+func TestCache_PerEntryTTL(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Hour)
+
+	cacheRepo.Set("short", []byte(`{}`), 20*time.Millisecond)
+	cacheRepo.Set("default", []byte(`{}`), 0)
+
+	info, found := cacheRepo.Inspect("default")
+	assert.True(t, found)
+	assert.InDelta(t, float64(time.Hour), float64(info.TTL), float64(time.Second))
+
+	time.Sleep(40 * time.Millisecond)
+
+	// Элемент с собственным TTL истек, элемент с TTL по умолчанию остался
+	_, found = cacheRepo.Get("short")
+	assert.False(t, found)
+	assert.False(t, cacheRepo.Has("short"))
+	assert.True(t, cacheRepo.Has("default"))
+}
+
+func TestTTLPolicy(t *testing.T) {
+	policy := models.TTLPolicy{
+		Recent:       5 * time.Minute,
+		Archive:      24 * time.Hour,
+		RecentWindow: 72 * time.Hour,
+	}
+
+	assert.Equal(t, 5*time.Minute, policy.For(time.Now().Add(-time.Hour)))
+	assert.Equal(t, 24*time.Hour, policy.For(time.Now().AddDate(0, -1, 0)))
+	assert.Equal(t, 5*time.Minute, policy.For(time.Time{}))
+
+	// Без окна все заказы считаются свежими
+	assert.Equal(t, time.Duration(0), models.TTLPolicy{}.For(time.Now().AddDate(-1, 0, 0)))
+}
+
+func TestRefreshAheadCache(t *testing.T) {
+	inner := cache.NewCache(time.Hour)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) ([]byte, time.Duration, error) {
+		loads.Add(1)
+		<-release
+		return []byte(`{"version":2}`), time.Hour, nil
+	}
+	refreshing := cache.NewRefreshAheadCache(inner, loader, time.Minute, time.Second)
+
+	// Далеко до истечения — перезагрузки нет
+	refreshing.Set("fresh", []byte(`{"version":1}`), time.Hour)
+	_, found := refreshing.Get("fresh")
+	assert.True(t, found)
+
+	// Близко к истечению — старые данные отдаются, перезагрузка запускается один раз
+	refreshing.Set("expiring", []byte(`{"version":1}`), 30*time.Second)
+	for i := 0; i < 3; i++ {
+		data, found := refreshing.Get("expiring")
+		assert.True(t, found)
+		assert.JSONEq(t, `{"version":1}`, string(data))
+	}
+
+	close(release)
+	refreshing.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	data, found := refreshing.Get("expiring")
+	assert.True(t, found)
+	assert.JSONEq(t, `{"version":2}`, string(data))
+
+	info, _ := inner.Inspect("expiring")
+	assert.Greater(t, info.TTL, 59*time.Minute)
+}
+
+func TestRefreshAheadCache_LoaderError(t *testing.T) {
+	inner := cache.NewCache(time.Hour)
+	loader := func(ctx context.Context, key string) ([]byte, time.Duration, error) {
+		return nil, 0, errors.New("database error")
+	}
+	refreshing := cache.NewRefreshAheadCache(inner, loader, time.Minute, time.Second)
+
+	refreshing.Set("expiring", []byte(`{"version":1}`), 30*time.Second)
+	_, found := refreshing.Get("expiring")
+	assert.True(t, found)
+	refreshing.Wait()
+
+	// Ошибка загрузки не удаляет текущие данные
+	data, found := refreshing.Get("expiring")
+	assert.True(t, found)
+	assert.JSONEq(t, `{"version":1}`, string(data))
+}
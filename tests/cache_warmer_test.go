@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	appuse "order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func TestCacheWarmer_Run(t *testing.T) {
+	mockRepo := new(mocks.OrderRepository)
+	cacheRepo := cache.NewCache(time.Minute)
+
+	created := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	// Первая страница: один заказ со снимком, один без него
+	mockRepo.On("ListOrderSnapshots", mock.Anything, models.OrderPageQuery{Limit: 2}).
+		Return([]models.OrderSnapshot{
+			{OrderUID: "order-1", DateCreated: created, Data: []byte(`{"order_uid":"order-1"}`)},
+			{OrderUID: "order-2", DateCreated: created.Add(-time.Hour)},
+		}, nil).Once()
+
+	// Вторая страница запрашивается с курсором последнего заказа и оказывается неполной
+	mockRepo.On("ListOrderSnapshots", mock.Anything, models.OrderPageQuery{
+		Limit:        2,
+		AfterCreated: created.Add(-time.Hour),
+		AfterUID:     "order-2",
+	}).Return([]models.OrderSnapshot{
+		{OrderUID: "order-3", DateCreated: created.Add(-2 * time.Hour), Data: []byte(`{"order_uid":"order-3"}`)},
+	}, nil).Once()
+
+	mockRepo.On("GetOrder", mock.Anything, "order-2").
+		Return(&models.Order{OrderUID: "order-2"}, nil).Once()
+	mockRepo.On("CacheOrderData", mock.Anything, "order-2", mock.Anything).Return(nil).Once()
+
+	warmer := appuse.NewCacheWarmer(mockRepo, cacheRepo, appuse.WarmupOptions{BatchSize: 2, Concurrency: 2})
+	err := warmer.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, cacheRepo.Has("order-1"))
+	assert.True(t, cacheRepo.Has("order-2"))
+	assert.True(t, cacheRepo.Has("order-3"))
+
+	progress := warmer.Progress()
+	assert.False(t, progress.Running)
+	assert.Equal(t, int64(3), progress.Loaded)
+	assert.Equal(t, int64(0), progress.Failed)
+	assert.Equal(t, int64(2), progress.Batches)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheWarmer_RunRespectsWindow(t *testing.T) {
+	mockRepo := new(mocks.OrderRepository)
+	cacheRepo := cache.NewCache(time.Minute)
+
+	// Ограничение MaxOrders уменьшает размер страницы, MaxAge задает нижнюю границу даты
+	mockRepo.On("ListOrderSnapshots", mock.Anything, mock.MatchedBy(func(q models.OrderPageQuery) bool {
+		return q.Limit == 1 && !q.Since.IsZero() && time.Since(q.Since) >= 24*time.Hour
+	})).Return([]models.OrderSnapshot{
+		{OrderUID: "order-1", DateCreated: time.Now(), Data: []byte(`{"order_uid":"order-1"}`)},
+	}, nil).Once()
+
+	warmer := appuse.NewCacheWarmer(mockRepo, cacheRepo, appuse.WarmupOptions{
+		BatchSize: 10,
+		MaxAge:    24 * time.Hour,
+		MaxOrders: 1,
+	})
+	err := warmer.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, cacheRepo.Has("order-1"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCacheWarmer_RunStopsOnError(t *testing.T) {
+	mockRepo := new(mocks.OrderRepository)
+	cacheRepo := cache.NewCache(time.Minute)
+
+	mockRepo.On("ListOrderSnapshots", mock.Anything, mock.Anything).
+		Return(nil, errors.New("database error")).Once()
+
+	warmer := appuse.NewCacheWarmer(mockRepo, cacheRepo, appuse.WarmupOptions{BatchSize: 10})
+	err := warmer.Run(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, warmer.Progress().LastError, "database error")
+}
+
+func TestCacheWarmer_RunCanceled(t *testing.T) {
+	mockRepo := new(mocks.OrderRepository)
+	cacheRepo := cache.NewCache(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	warmer := appuse.NewCacheWarmer(mockRepo, cacheRepo, appuse.WarmupOptions{BatchSize: 10})
+	err := warmer.Run(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	mockRepo.AssertNotCalled(t, "ListOrderSnapshots", mock.Anything, mock.Anything)
+}
+
+func TestCacheWarmer_TryStartRejectsConcurrentStart(t *testing.T) {
+	mockRepo := new(mocks.OrderRepository)
+	cacheRepo := cache.NewCache(time.Minute)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockRepo.On("ListOrderSnapshots", mock.Anything, mock.Anything).
+		Return([]models.OrderSnapshot{}, nil).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).Once()
+
+	warmer := appuse.NewCacheWarmer(mockRepo, cacheRepo, appuse.WarmupOptions{BatchSize: 10})
+	done, err := warmer.TryStart(context.Background(), false)
+	assert.NoError(t, err)
+	<-started
+
+	// Пока первый прогрев идет, второй запуск отклоняется сразу
+	_, err = warmer.TryStart(context.Background(), true)
+	assert.ErrorIs(t, err, models.ErrWarmupInProgress)
+
+	close(release)
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("warm-up did not finish")
+	}
+	assert.False(t, warmer.Progress().Running)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/config"
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func TestConfigReload_AppliesReloadableSettings(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "cache:\n  ttl: 10m\nserver:\n  port: 8081\n")
+	args := []string{"-config", path}
+
+	cfg, err := config.Load(args, devEnv(nil))
+	require.NoError(t, err)
+	reloader := config.NewReloader(cfg, args, devEnv(nil))
+
+	var applied []time.Duration
+	reloader.Subscribe("cache", func(cfg *config.Config) error {
+		applied = append(applied, cfg.CacheTTL)
+		return nil
+	})
+	reloader.Subscribe("broken", func(cfg *config.Config) error {
+		return errors.New("cannot apply")
+	})
+
+	// Без изменений подписчики не вызываются
+	result := reloader.Reload(models.ReloadTriggerAPI)
+	assert.Equal(t, models.ReloadStatusUnchanged, result.Status)
+	assert.Empty(t, applied)
+
+	require.NoError(t, os.WriteFile(path, []byte("cache:\n  ttl: 2m\nserver:\n  port: 9000\n"), 0o600))
+	result = reloader.Reload(models.ReloadTriggerFile)
+
+	assert.Equal(t, models.ReloadStatusPartial, result.Status)
+	assert.Equal(t, []string{"cache.ttl"}, result.Changed)
+	assert.Equal(t, []string{"server.port"}, result.RestartRequired)
+	assert.Equal(t, []string{"broken: cannot apply"}, result.Errors)
+	assert.Equal(t, []time.Duration{2 * time.Minute}, applied)
+
+	// Порт требует перезапуска и остается прежним
+	current := reloader.Current()
+	assert.Equal(t, 2*time.Minute, current.CacheTTL)
+	assert.Equal(t, 8081, current.ServerPort)
+
+	last, ok := reloader.LastReload()
+	require.True(t, ok)
+	assert.Equal(t, result, last)
+}
+
+func TestConfigReload_InvalidConfigIsNotApplied(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "log:\n  level: info\n")
+	args := []string{"-config", path}
+
+	cfg, err := config.Load(args, devEnv(nil))
+	require.NoError(t, err)
+	reloader := config.NewReloader(cfg, args, devEnv(nil))
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: verbose\ncache:\n  ttl: 1m\n"), 0o600))
+	result := reloader.Reload(models.ReloadTriggerSignal)
+
+	assert.Equal(t, models.ReloadStatusFailed, result.Status)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "log.level")
+	assert.Equal(t, 30*time.Minute, reloader.Current().CacheTTL)
+}
+
+func TestConfigReload_AdminEndpointAndFeatures(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "features:\n  batch_get: true\n")
+	args := []string{"-config", path}
+	cfg, err := config.Load(args, devEnv(nil))
+	require.NoError(t, err)
+	reloader := config.NewReloader(cfg, args, devEnv(nil))
+
+	cacheRepo := cache.NewCache(time.Minute)
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	server.EnableAdmin(new(mocks.CacheWarmer))
+	server.SetConfigReloader(reloader)
+	reloader.Subscribe("http", func(cfg *config.Config) error {
+		server.SetFeatures(orderhttp.Features{OrderStream: cfg.FeatureOrderStream, BatchGet: cfg.FeatureBatchGet})
+		return nil
+	})
+	handler := server.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/config/reload"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"reloaded": false}`, rec.Body.String())
+
+	require.NoError(t, os.WriteFile(path, []byte("features:\n  batch_get: false\n"), 0o600))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/config/reload"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result models.ConfigReload
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, models.ReloadTriggerAPI, result.Trigger)
+	assert.Equal(t, models.ReloadStatusApplied, result.Status)
+	assert.Equal(t, []string{"features.batch_get"}, result.Changed)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders:batchGet", strings.NewReader(`{"order_uids":["a"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", testSupportKey)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "feature_disabled", decodeProblem(t, rec).Code)
+
+	// Некорректная конфигурация возвращает 422 и не применяется
+	require.NoError(t, os.WriteFile(path, []byte("features:\n  batch_get: maybe\n"), 0o600))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/config/reload"))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"failed"`)
+}
+
+func TestCache_MaxEntriesEvictsOldest(t *testing.T) {
+	c := cache.NewCache(time.Minute)
+	c.SetMaxEntries(2)
+
+	c.Set("a", []byte("1"), 0)
+	time.Sleep(time.Millisecond)
+	c.Set("b", []byte("2"), 0)
+	time.Sleep(time.Millisecond)
+	c.Set("c", []byte("3"), 0)
+
+	assert.False(t, c.Has("a"))
+	assert.True(t, c.Has("b"))
+	assert.True(t, c.Has("c"))
+
+	// Уменьшение предела вытесняет лишние элементы сразу
+	c.SetMaxEntries(1)
+	assert.Equal(t, int64(1), c.Stats().Entries)
+	assert.True(t, c.Has("c"))
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/events"
+	ordergrpc "order-service/internal/infrastructure/grpc"
+	"order-service/mocks"
+	orderv1 "order-service/pkg/api/order/v1"
+)
+
+// newGRPCTestClient поднимает gRPC-сервер поверх bufconn и возвращает соединение с ним
+func newGRPCTestClient(t *testing.T, orders *mocks.OrderService, bus *events.Broker) *grpc.ClientConn {
+	t.Helper()
+	return serveGRPCTest(t, ordergrpc.NewOrderGRPCServer(0, orders, bus))
+}
+
+// serveGRPCTest запускает сервер на bufconn и возвращает подключенного клиента
+func serveGRPCTest(t *testing.T, server *ordergrpc.OrderGRPCServer) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+
+	return conn
+}
+
+func TestGRPC_GetOrder(t *testing.T) {
+	mockOrders := new(mocks.OrderService)
+	client := orderv1.NewOrderServiceClient(newGRPCTestClient(t, mockOrders, nil))
+
+	created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	mockOrders.On("Get", mock.Anything, "test-order").Return(&models.Order{
+		OrderUID:    "test-order",
+		Payment:     models.Payment{Currency: "USD", Amount: 1817},
+		Items:       []models.Item{{ChrtID: 9934930, Name: "Mascaras"}},
+		DateCreated: created,
+	}, nil)
+	mockOrders.On("Get", mock.Anything, "missing").Return(nil, models.ErrOrderNotFound)
+	mockOrders.On("Get", mock.Anything, "bad uid").Return(nil, models.ErrInvalidOrderUID)
+	mockOrders.On("Get", mock.Anything, "broken").Return(nil, errors.New("connection refused"))
+
+	resp, err := client.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: "test-order"})
+	require.NoError(t, err)
+	assert.Equal(t, "test-order", resp.Order.OrderUid)
+	assert.Equal(t, int64(1817), resp.Order.Payment.Amount)
+	assert.Equal(t, "Mascaras", resp.Order.Items[0].Name)
+	assert.True(t, created.Equal(resp.Order.DateCreated.AsTime()))
+
+	for uid, code := range map[string]codes.Code{
+		"missing": codes.NotFound,
+		"bad uid": codes.InvalidArgument,
+		"broken":  codes.Internal,
+	} {
+		_, err := client.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: uid})
+		assert.Equal(t, code, status.Code(err), uid)
+	}
+}
+
+func TestGRPC_BatchGetOrders(t *testing.T) {
+	mockOrders := new(mocks.OrderService)
+	client := orderv1.NewOrderServiceClient(newGRPCTestClient(t, mockOrders, nil))
+
+	uids := []string{"order-1", "missing"}
+	mockOrders.On("GetMany", mock.Anything, uids).
+		Return([]models.Order{{OrderUID: "order-1"}}, []string{"missing"}, nil).Once()
+
+	resp, err := client.BatchGetOrders(context.Background(), &orderv1.BatchGetOrdersRequest{OrderUids: uids})
+	require.NoError(t, err)
+	require.Len(t, resp.Orders, 1)
+	assert.Equal(t, "order-1", resp.Orders[0].OrderUid)
+	assert.Equal(t, []string{"missing"}, resp.MissingOrderUids)
+	mockOrders.AssertExpectations(t)
+
+	mockOrders.On("GetMany", mock.Anything, mock.Anything).Return(nil, nil, models.ErrBatchTooLarge).Once()
+	_, err = client.BatchGetOrders(context.Background(), &orderv1.BatchGetOrdersRequest{
+		OrderUids: make([]string, models.MaxBatchOrders+1),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_ListOrders(t *testing.T) {
+	mockOrders := new(mocks.OrderService)
+	client := orderv1.NewOrderServiceClient(newGRPCTestClient(t, mockOrders, nil))
+
+	newest := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	older := newest.Add(-time.Hour)
+
+	// Первая страница короче page_size (заказ пропущен при загрузке), но выборка в БД была полной
+	mockOrders.On("List", mock.Anything, models.OrderPageQuery{Limit: 2}).Return(models.OrderPage{
+		Orders: []models.Order{{OrderUID: "order-2", DateCreated: newest}},
+		Next:   &models.OrderPageCursor{Created: older, UID: "order-1"},
+	}, nil).Once()
+	mockOrders.On("List", mock.Anything, mock.MatchedBy(func(q models.OrderPageQuery) bool {
+		return q.Limit == 2 && q.AfterUID == "order-1" && q.AfterCreated.Equal(older)
+	})).Return(models.OrderPage{Orders: []models.Order{{OrderUID: "order-0", DateCreated: older}}}, nil).Once()
+
+	first, err := client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, first.Orders, 1)
+	require.NotEmpty(t, first.NextPageToken)
+
+	second, err := client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{PageSize: 2, PageToken: first.NextPageToken})
+	require.NoError(t, err)
+	assert.Len(t, second.Orders, 1)
+	assert.Empty(t, second.NextPageToken)
+	mockOrders.AssertExpectations(t)
+
+	_, err = client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{PageToken: "not-a-token"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_WatchOrders(t *testing.T) {
+	bus := events.NewBroker(16)
+	client := orderv1.NewOrderServiceClient(newGRPCTestClient(t, new(mocks.OrderService), bus))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchOrders(ctx, &orderv1.WatchOrdersRequest{})
+	require.NoError(t, err)
+
+	// Подписка регистрируется асинхронно, поэтому публикуем до получения первого события
+	received := make(chan *orderv1.OrderEvent, 1)
+	go func() {
+		event, err := stream.Recv()
+		if err == nil {
+			received <- event
+		}
+	}()
+
+	var event *orderv1.OrderEvent
+	for event == nil {
+		bus.Publish(models.OrderEvent{Type: models.OrderUpdated, Order: models.Order{OrderUID: "order-1"}})
+		select {
+		case event = <-received:
+		case <-time.After(20 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("order event was not received")
+		}
+	}
+
+	assert.Equal(t, orderv1.OrderEventType_ORDER_EVENT_TYPE_UPDATED, event.Type)
+	assert.Equal(t, "order-1", event.Order.OrderUid)
+	assert.NotZero(t, event.Id)
+}
+
+func TestGRPC_Health(t *testing.T) {
+	conn := newGRPCTestClient(t, new(mocks.OrderService), nil)
+	client := healthpb.NewHealthClient(conn)
+
+	for _, service := range []string{"", orderv1.OrderService_ServiceDesc.ServiceName} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+// Заказ достаточного размера, чтобы ответ сжимался
+const cachedOrderJSON = `{"order_uid":"test-order","track_number":"WBILMTESTTRACK","entry":"WBIL",` +
+	`"delivery":{"name":"Test Testov","phone":"+9720000000","zip":"2639809","city":"Kiryat Mozkin","address":"Ploshad Mira 15","region":"Kraiot","email":"test@gmail.com"},` +
+	`"payment":{"transaction":"test-order","currency":"USD","provider":"wbpay","amount":1817,"payment_dt":1637907727,"bank":"alpha","delivery_cost":1500,"goods_total":317},` +
+	`"items":[{"chrt_id":9934930,"track_number":"WBILMTESTTRACK","price":453,"rid":"ab4219087a764ae0btest","name":"Mascaras","sale":30,"size":"0","total_price":317,"nm_id":2389212,"brand":"Vivienne Sabo","status":202}],` +
+	`"locale":"en","customer_id":"test","delivery_service":"meest","shardkey":"9","sm_id":99,"date_created":"2021-11-26T06:22:19Z","oof_shard":"1"}`
+
+func newCachingTestServer(t *testing.T, maxAge time.Duration) http.Handler {
+	cacheRepo := cache.NewCache(time.Minute)
+	cacheRepo.Set("test-order", []byte(cachedOrderJSON), 0)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetCacheMaxAge(maxAge)
+	return server.Handler()
+}
+
+func getOrder(handler http.Handler, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/test-order", nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOrderAPI_ConditionalRequests(t *testing.T) {
+	handler := newCachingTestServer(t, 0)
+
+	rec := getOrder(handler, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `"`), etag)
+	// Время записи в кеш нестабильно, поэтому валидатор только ETag
+	assert.Empty(t, rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+
+	// Тот же кешированный заказ дает тот же ETag
+	assert.Equal(t, etag, getOrder(handler, nil).Header().Get("ETag"))
+
+	notModified := getOrder(handler, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.Bytes())
+	assert.Equal(t, etag, notModified.Header().Get("ETag"))
+
+	// Без Last-Modified условие If-Modified-Since не применяется
+	assert.Equal(t, http.StatusOK,
+		getOrder(handler, http.Header{"If-Modified-Since": {time.Now().UTC().Format(http.TimeFormat)}}).Code)
+	assert.Equal(t, http.StatusOK, getOrder(handler, http.Header{"If-None-Match": {`"stale"`}}).Code)
+}
+
+func TestOrderAPI_CacheControlMaxAge(t *testing.T) {
+	handler := newCachingTestServer(t, 5*time.Minute)
+
+	rec := getOrder(handler, nil)
+	assert.Equal(t, "private, max-age=300", rec.Header().Get("Cache-Control"))
+}
+
+func TestOrderAPI_Compression(t *testing.T) {
+	handler := newCachingTestServer(t, 0)
+	plain := getOrder(handler, nil)
+	require.Equal(t, http.StatusOK, plain.Code)
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+		decode         func(io.Reader) (io.Reader, error)
+	}{
+		{"gzip", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{"gzip, br", "br", func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }},
+		{"br;q=0.5, gzip", "gzip", func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			rec := getOrder(handler, http.Header{"Accept-Encoding": {tt.acceptEncoding}})
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.encoding, rec.Header().Get("Content-Encoding"))
+			assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+			assert.Equal(t, "W/"+plain.Header().Get("ETag"), rec.Header().Get("ETag"))
+
+			reader, err := tt.decode(rec.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, plain.Body.String(), string(body))
+
+			// Слабый ETag сжатого ответа подходит для перепроверки
+			notModified := getOrder(handler, http.Header{
+				"Accept-Encoding": {tt.acceptEncoding},
+				"If-None-Match":   {rec.Header().Get("ETag")},
+			})
+			assert.Equal(t, http.StatusNotModified, notModified.Code)
+			assert.Empty(t, notModified.Header().Get("Content-Encoding"))
+		})
+	}
+
+	// Короткие ответы и отказ от кодировок оставляют тело как есть
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/bad$uid", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	assert.Empty(t, getOrder(handler, http.Header{"Accept-Encoding": {"gzip;q=0, identity"}}).Header().Get("Content-Encoding"))
+}
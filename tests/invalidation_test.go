@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/infrastructure/kafka"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func TestInvalidationSubscriber_Apply(t *testing.T) {
+	local := cache.NewCache(time.Minute)
+	subscriber := kafka.NewInvalidationSubscriber(nil, "invalidation", "group", "replica-a", local)
+
+	local.Set("order-1", []byte(`{}`), 0)
+	local.Set("order-2", []byte(`{}`), 0)
+	local.Set("test-1", []byte(`{}`), 0)
+
+	// Собственные инвалидации реплики игнорируются
+	subscriber.Apply(models.CacheInvalidation{Origin: "replica-a", Keys: []string{"order-1"}})
+	assert.True(t, local.Has("order-1"))
+
+	subscriber.Apply(models.CacheInvalidation{Origin: "replica-b", Keys: []string{"order-1"}})
+	assert.False(t, local.Has("order-1"))
+
+	subscriber.Apply(models.CacheInvalidation{Origin: "replica-b", Prefix: "test-"})
+	assert.False(t, local.Has("test-1"))
+	assert.True(t, local.Has("order-2"))
+
+	subscriber.Apply(models.CacheInvalidation{Origin: "replica-b", Flush: true})
+	assert.False(t, local.Has("order-2"))
+}
+
+func TestAdmin_EvictPublishesInvalidation(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockPublisher := new(mocks.InvalidationPublisher)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	server.EnableAdmin(new(mocks.CacheWarmer))
+	server.SetInvalidationPublisher(mockPublisher)
+	handler := server.Handler()
+
+	mockPublisher.On("PublishInvalidation", mock.Anything, mock.MatchedBy(func(inv models.CacheInvalidation) bool {
+		return len(inv.Keys) == 1 && inv.Keys[0] == "order-1" && inv.Reason == "admin_evict"
+	})).Return(nil).Once()
+	mockPublisher.On("PublishInvalidation", mock.Anything, mock.MatchedBy(func(inv models.CacheInvalidation) bool {
+		return inv.Flush
+	})).Return(nil).Once()
+
+	cacheRepo.Set("order-1", []byte(`{}`), 0)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodDelete, "/admin/cache/keys/order-1"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/cache/flush"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mockPublisher.AssertExpectations(t)
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/logger"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+// setupTestLogger направляет логгер в буфер и восстанавливает прежнее состояние после теста
+func setupTestLogger(t *testing.T, opts logger.Options) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	previousLevel, previousComponents := logger.Level(), logger.ComponentLevels()
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		logger.SetLevel(previousLevel)
+		logger.SetComponentLevels(previousComponents)
+	})
+
+	opts.Output = &buf
+	require.NoError(t, logger.Setup(opts))
+	return &buf
+}
+
+func TestLogger_ComponentLevels(t *testing.T) {
+	buf := setupTestLogger(t, logger.Options{Level: slog.LevelInfo, Format: logger.FormatJSON})
+
+	slog.Debug("hidden debug")
+	slog.Info("visible info")
+
+	// Компонент тестов — последний элемент пути пакета: order-service/tests
+	logger.SetComponentLevels(map[string]slog.Level{"tests": slog.LevelDebug})
+	slog.Debug("component debug")
+
+	logger.SetComponentLevels(map[string]slog.Level{"tests": slog.LevelError, "kafka": slog.LevelDebug})
+	slog.Warn("suppressed warn")
+
+	output := buf.String()
+	assert.NotContains(t, output, "hidden debug")
+	assert.Contains(t, output, "visible info")
+	assert.Contains(t, output, "component debug")
+	assert.NotContains(t, output, "suppressed warn")
+
+	_, err := logger.ParseComponentLevels("kafka=debug,postgres")
+	assert.Error(t, err)
+}
+
+func TestLogger_PrettyFormat(t *testing.T) {
+	buf := setupTestLogger(t, logger.Options{Level: slog.LevelDebug, Format: logger.FormatPretty, AddSource: true})
+
+	slog.With("component", "test").WithGroup("order").Debug("order saved", "uid", "b563feb7", "note", "two words")
+
+	line := buf.String()
+	assert.Contains(t, line, "DEBUG order saved (logger_test.go:")
+	assert.Contains(t, line, "component=test order.uid=b563feb7 order.note=\"two words\"")
+	// В буфер, а не в терминал, цвета не выводятся
+	assert.NotContains(t, line, "\033[")
+	assert.True(t, strings.HasSuffix(line, "\n"))
+}
+
+func TestLogger_RotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "service.log")
+	file, err := logger.OpenRotatingFile(path, 1, 2)
+	require.NoError(t, err)
+	defer file.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 4; i++ {
+		_, err := file.Write(chunk)
+		require.NoError(t, err)
+	}
+
+	// Храним не больше двух старых файлов
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, int64(len(chunk)), info.Size(), name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLogger_AdminLevelEndpoint(t *testing.T) {
+	setupTestLogger(t, logger.Options{Level: slog.LevelInfo, Format: logger.FormatJSON})
+
+	cacheRepo := cache.NewCache(time.Minute)
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	server.EnableAdmin(new(mocks.CacheWarmer))
+	handler := server.Handler()
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/log/level", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := put(`{"level":"warn","components":{"kafka":"debug"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"warn","components":{"kafka":"debug"}}`, rec.Body.String())
+	assert.Equal(t, slog.LevelWarn, logger.Level())
+	assert.Equal(t, map[string]slog.Level{"kafka": slog.LevelDebug}, logger.ComponentLevels())
+
+	// Без components меняется только общий уровень
+	rec = put(`{"level":"error"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]slog.Level{"kafka": slog.LevelDebug}, logger.ComponentLevels())
+
+	rec = put(`{"components":{"kafka":"verbose"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_request_body", decodeProblem(t, rec).Code)
+	assert.Equal(t, slog.LevelError, logger.Level())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/log/level"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"error","components":{"kafka":"debug"}}`, rec.Body.String())
+}
+
+func TestLogger_RedactsSensitiveData(t *testing.T) {
+	buf := setupTestLogger(t, logger.Options{
+		Level:  slog.LevelInfo,
+		Format: logger.FormatJSON,
+		Redact: &logger.RedactOptions{Allow: []string{"city"}, Deny: []string{"transaction"}},
+	})
+
+	// Сообщение, которое не удалось разобрать: обрезанный JSON заказа
+	raw := `{"order_uid":"b563feb7","delivery":{"name":"Test Testov","phone":"+9720000000","zip":"2639809",` +
+		`"city":"Kiryat Mozkin","email":"test@gmail.com"},"payment":{"transaction":"b563feb7b2b84b6test","amount":1817`
+	slog.With("access_token", "secret-token").Error("Failed to parse message for ivan@example.com",
+		"raw_message", raw,
+		"error", errors.New("card 4111 1111 1111 1111 declined"),
+		slog.Group("customer", "contact", "+7 (999) 123-45-67", "order_uid", "b563feb7"),
+		slog.Group("delivery", "name", "Test Testov"),
+		"chrt_id", 9934930,
+		// Общие имена полей вне данных заказа не маскируются
+		"address", ":8081", "name", "order_consumer")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Failed to parse message for [EMAIL]", record["msg"])
+	assert.Equal(t, logger.Redacted, record["access_token"])
+	assert.Equal(t, map[string]any{"name": logger.Redacted}, record["delivery"])
+	assert.Equal(t, ":8081", record["address"])
+	assert.Equal(t, "order_consumer", record["name"])
+	assert.Equal(t, "card [CARD] declined", record["error"])
+	assert.Equal(t, map[string]any{"contact": "[PHONE]", "order_uid": "b563feb7"}, record["customer"])
+	assert.Equal(t, float64(9934930), record["chrt_id"])
+
+	rawMessage := record["raw_message"].(string)
+	for _, leaked := range []string{"Test Testov", "+9720000000", "2639809", "test@gmail.com", "b563feb7b2b84b6test"} {
+		assert.NotContains(t, rawMessage, leaked)
+	}
+	assert.Contains(t, rawMessage, `"order_uid":"b563feb7"`)
+	assert.Contains(t, rawMessage, `"phone":"[REDACTED]"`)
+	// Поле из списка разрешенных не маскируется
+	assert.Contains(t, rawMessage, `"city":"Kiryat Mozkin"`)
+	assert.Contains(t, rawMessage, `"amount":1817`)
+
+	// Путь учитывает группы, открытые через WithGroup
+	buf.Reset()
+	slog.Default().WithGroup("order").WithGroup("delivery").Info("Order received", "address", "Ploshad Mira 15", "zone", "north")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, map[string]any{"delivery": map[string]any{"address": logger.Redacted, "zone": "north"}}, record["order"])
+}
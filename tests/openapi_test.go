@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/config"
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func init() {
+	openapi3filter.RegisterBodyDecoder("application/problem+json", openapi3filter.JSONBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/html", func(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (any, error) {
+		data, err := io.ReadAll(body)
+		return string(data), err
+	})
+}
+
+func loadOpenAPISpec(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(orderhttp.OpenAPISpec())
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(loader.Context))
+	return doc
+}
+
+func TestOpenAPI_SpecServed(t *testing.T) {
+	_, _, handler := newOrderAPITestServer(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, string(orderhttp.OpenAPISpec()), rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/api/openapi.json")
+}
+
+// Ответы реальных обработчиков должны соответствовать спецификации
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	doc := loadOpenAPISpec(t)
+	router, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+	mockWarmer := new(mocks.CacheWarmer)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(mockRepo, cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	server.EnableAdmin(mockWarmer)
+	cfg, err := config.Load(nil, devEnv(nil))
+	require.NoError(t, err)
+	server.SetConfigReloader(config.NewReloader(cfg, nil, devEnv(nil)))
+	handler := server.Handler()
+
+	order := &models.Order{
+		OrderUID:    "spec-order",
+		TrackNumber: "WBILMTESTTRACK",
+		Delivery:    models.Delivery{Name: "Test Testov", Phone: "+9720000000"},
+		Payment:     models.Payment{Transaction: "spec-order", Currency: "USD", Amount: 1817},
+		Items:       []models.Item{{ChrtID: 9934930, Price: 453, Name: "Mascaras"}},
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+	}
+	mockRepo.On("GetOrder", mock.Anything, "spec-order").Return(order, nil)
+	mockRepo.On("GetOrder", mock.Anything, "missing").Return(nil, sql.ErrNoRows)
+	mockRepo.On("GetOrders", mock.Anything, mock.Anything).Return([]models.Order{}, nil)
+	mockWarmer.On("Progress").Return(models.WarmupProgress{})
+	warmupDone := make(chan error)
+	close(warmupDone)
+	mockWarmer.On("TryStart", mock.Anything, mock.Anything).Return((<-chan error)(warmupDone), nil).Maybe()
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		key    string
+		status int
+	}{
+		{http.MethodGet, "/api/v1/orders/spec-order", "", testSupportKey, http.StatusOK},
+		{http.MethodGet, "/api/v1/orders/stream", "", testSupportKey, http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/orders:batchGet", `{"order_uids":["spec-order","missing"]}`, testSupportKey, http.StatusOK},
+		{http.MethodGet, "/api/v1/orders/missing", "", testSupportKey, http.StatusNotFound},
+		{http.MethodGet, "/api/v1/orders/bad.uid", "", testSupportKey, http.StatusBadRequest},
+		{http.MethodGet, "/orders/missing", "", testSupportKey, http.StatusNotFound},
+		{http.MethodGet, "/order?id=spec-order&format=json", "", "", http.StatusPermanentRedirect},
+		{http.MethodGet, "/health", "", "", http.StatusOK},
+		{http.MethodGet, "/api/openapi.json", "", "", http.StatusOK},
+		{http.MethodGet, "/api/docs", "", "", http.StatusOK},
+		{http.MethodGet, "/admin/cache/stats", "", testAdminToken, http.StatusOK},
+		{http.MethodGet, "/admin/cache/stats", "", "", http.StatusUnauthorized},
+		{http.MethodGet, "/admin/cache/stats", "", testSupportKey, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/orders/spec-order", "", "", http.StatusUnauthorized},
+		{http.MethodGet, "/admin/cache/keys/spec-order", "", testAdminToken, http.StatusOK},
+		{http.MethodGet, "/admin/cache/keys/unknown", "", testAdminToken, http.StatusNotFound},
+		{http.MethodDelete, "/admin/cache/keys/spec-order", "", testAdminToken, http.StatusOK},
+		{http.MethodDelete, "/admin/cache/keys?prefix=spec-", "", testAdminToken, http.StatusOK},
+		{http.MethodPost, "/admin/cache/flush", "", testAdminToken, http.StatusOK},
+		{http.MethodPost, "/admin/cache/warmup", "", testAdminToken, http.StatusAccepted},
+		{http.MethodGet, "/admin/log/level", "", testAdminToken, http.StatusOK},
+		{http.MethodPut, "/admin/log/level", `{"level":"info","components":{}}`, testAdminToken, http.StatusOK},
+		{http.MethodGet, "/admin/config/reload", "", testAdminToken, http.StatusOK},
+		{http.MethodPost, "/admin/config/reload", "", testAdminToken, http.StatusOK},
+	}
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.status, rec.Code, rec.Body.String())
+
+			route, pathParams, err := router.FindRoute(req)
+			require.NoError(t, err)
+			covered[route.Path] = true
+
+			requestInput := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+			}
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: requestInput,
+				Status:                 rec.Code,
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(rec.Body),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			}
+			assert.NoError(t, openapi3filter.ValidateResponse(context.Background(), responseInput))
+		})
+	}
+
+	// Каждый описанный маршрут должен быть проверен хотя бы одним запросом
+	for path := range doc.Paths.Map() {
+		assert.True(t, covered[path], "path %s is not covered by the contract test", path)
+	}
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func newOrderAPITestServer(t *testing.T) (*cache.Cache, *mocks.OrderRepository, http.Handler) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(mockRepo, cacheRepo), cacheRepo)
+
+	return cacheRepo, mockRepo, server.Handler()
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) orderhttp.Problem {
+	t.Helper()
+
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+	var problem orderhttp.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, rec.Code, problem.Status)
+	return problem
+}
+
+func TestOrderAPI_GetFromCache(t *testing.T) {
+	cacheRepo, mockRepo, handler := newOrderAPITestServer(t)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order","track_number":"TRACK"}`), 0)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/orders/test-order", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var order models.Order
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	assert.Equal(t, "test-order", order.OrderUID)
+	assert.Equal(t, "TRACK", order.TrackNumber)
+	mockRepo.AssertNotCalled(t, "GetOrder", mock.Anything, mock.Anything)
+}
+
+func TestOrderAPI_GetFromDatabase(t *testing.T) {
+	cacheRepo, mockRepo, handler := newOrderAPITestServer(t)
+	mockRepo.On("GetOrder", mock.Anything, "db-order").
+		Return(&models.Order{OrderUID: "db-order"}, nil).Once()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/orders/db-order", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	// Найденный в БД заказ попадает в кэш
+	assert.True(t, cacheRepo.Has("db-order"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderAPI_Errors(t *testing.T) {
+	_, mockRepo, handler := newOrderAPITestServer(t)
+	mockRepo.On("GetOrder", mock.Anything, "missing").Return(nil, sql.ErrNoRows)
+	mockRepo.On("GetOrder", mock.Anything, "broken").Return(nil, errors.New("connection refused"))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		accept string
+		status int
+		code   string
+	}{
+		{"not found", http.MethodGet, "/api/v1/orders/missing", "", http.StatusNotFound, "order_not_found"},
+		{"database error", http.MethodGet, "/api/v1/orders/broken", "", http.StatusInternalServerError, "internal_error"},
+		{"invalid uid", http.MethodGet, "/api/v1/orders/bad%20uid", "", http.StatusBadRequest, "invalid_order_uid"},
+		{"not acceptable", http.MethodGet, "/api/v1/orders/missing", "text/html", http.StatusNotAcceptable, "not_acceptable"},
+		{"method not allowed", http.MethodDelete, "/api/v1/orders/missing", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"unknown route", http.MethodGet, "/api/v2/orders/missing", "", http.StatusNotFound, "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			problem := decodeProblem(t, rec)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.NotEmpty(t, problem.Instance)
+		})
+	}
+}
+
+func TestOrderAPI_AcceptNegotiation(t *testing.T) {
+	cacheRepo, _, handler := newOrderAPITestServer(t)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order"}`), 0)
+
+	for _, accept := range []string{"application/json", "application/*", "*/*", "text/html;q=0.9, application/json"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/test-order", nil)
+		req.Header.Set("Accept", accept)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, accept)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/test-order", nil)
+	req.Header.Set("Accept", "application/json;q=0, */*")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+}
+
+func TestOrderAPI_LegacyRedirect(t *testing.T) {
+	_, _, handler := newOrderAPITestServer(t)
+
+	tests := []struct {
+		target   string
+		accept   string
+		location string
+	}{
+		{"/order?id=test-order", "text/html", "/orders/test-order"},
+		{"/order?id=test-order&format=json", "", "/api/v1/orders/test-order"},
+		{"/order?id=test-order", "application/json", "/api/v1/orders/test-order"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Header.Set("Accept", tt.accept)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, rec.Code, tt.target)
+		assert.Equal(t, tt.location, rec.Header().Get("Location"), tt.target)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/order", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_order_uid", decodeProblem(t, rec).Code)
+}
+
+func batchGetRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders:batchGet", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestOrderAPI_BatchGet(t *testing.T) {
+	cacheRepo, mockRepo, handler := newOrderAPITestServer(t)
+	cacheRepo.Set("cached", []byte(`{"order_uid":"cached"}`), 0)
+
+	// Промахи кеша загружаются одним запросом; некорректный UID в БД не уходит
+	mockRepo.On("GetOrders", mock.Anything, []string{"from-db", "missing"}).
+		Return([]models.Order{{OrderUID: "from-db"}}, nil).Once()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, batchGetRequest(`{"order_uids":["from-db","cached","missing","bad uid","cached"]}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Orders           []models.Order `json:"orders"`
+		MissingOrderUIDs []string       `json:"missing_order_uids"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Orders, 2) {
+		assert.Equal(t, "from-db", resp.Orders[0].OrderUID)
+		assert.Equal(t, "cached", resp.Orders[1].OrderUID)
+	}
+	assert.Equal(t, []string{"missing", "bad uid"}, resp.MissingOrderUIDs)
+	assert.True(t, cacheRepo.Has("from-db"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderAPI_BatchGetLimits(t *testing.T) {
+	_, _, handler := newOrderAPITestServer(t)
+
+	tooMany := make([]string, models.MaxBatchOrders+1)
+	for i := range tooMany {
+		tooMany[i] = "order"
+	}
+	tooManyBody, _ := json.Marshal(map[string][]string{"order_uids": tooMany})
+
+	textReq := batchGetRequest(`{"order_uids":[]}`)
+	textReq.Header.Set("Content-Type", "text/plain")
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   string
+	}{
+		{"too many uids", batchGetRequest(string(tooManyBody)), http.StatusRequestEntityTooLarge, "batch_too_large"},
+		{"body too large", batchGetRequest(`{"order_uids":["` + strings.Repeat("a", 300<<10) + `"]}`), http.StatusRequestEntityTooLarge, "request_too_large"},
+		{"invalid json", batchGetRequest(`{"order_uids":`), http.StatusBadRequest, "invalid_request_body"},
+		{"unknown field", batchGetRequest(`{"ids":["a"]}`), http.StatusBadRequest, "invalid_request_body"},
+		{"wrong content type", textReq, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"wrong method", httptest.NewRequest(http.MethodGet, "/api/v1/orders:batchGet", nil), http.StatusMethodNotAllowed, "method_not_allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, decodeProblem(t, rec).Code)
+		})
+	}
+}
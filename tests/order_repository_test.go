@@ -3,12 +3,16 @@ package tests
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"order-service/internal/domain/models"
 	"order-service/internal/infrastructure/postgres"
@@ -35,6 +39,13 @@ func TestPostgresRepository_SaveOrder(t *testing.T) {
 
 	// Настраиваем ожидание запроса
 	// Обратите внимание, что мы должны настроить ожидание в соответствии с реальным SQL-запросом из репозитория
+	mock.ExpectBegin()
+
+	// Заказа еще нет в БД
+	mock.ExpectQuery("SELECT o.order_uid, c.data FROM orders o").
+		WithArgs(order.OrderUID).
+		WillReturnError(sql.ErrNoRows)
+
 	mock.ExpectExec("INSERT INTO orders").
 		WithArgs(order.OrderUID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
@@ -57,26 +68,113 @@ func TestPostgresRepository_SaveOrder(t *testing.T) {
 
 	// Для items также нужно настроить ожидания, если есть товары в заказе
 
+	// Снимок заказа пишется в той же транзакции
+	mock.ExpectExec("INSERT INTO order_cache").
+		WithArgs(order.OrderUID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
 	// Вызываем тестируемый метод
-	err = repo.SaveOrder(ctx, order)
+	result, err := repo.SaveOrder(ctx, order)
 
 	// Проверяем результаты
 	assert.NoError(t, err)
+	assert.Equal(t, models.OrderIngestCreated, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Тестируем корректировку существующего заказа: прежние товары заменяются новыми
+	correction := models.Order{
+		OrderUID: "test-order-123",
+		Items:    []models.Item{{ChrtID: 1}},
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.order_uid, c.data FROM orders o").
+		WithArgs(correction.OrderUID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "data"}).AddRow(correction.OrderUID, []byte(`{"order_uid":"test-order-123"}`)))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO delivery").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO payment").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM items WHERE order_uid = \\$1").
+		WithArgs(correction.OrderUID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO items").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO order_cache").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err = repo.SaveOrder(ctx, correction)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderIngestUpdated, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
 
 	// Тестируем случай с ошибкой
 	expectedError := errors.New("database error")
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.order_uid, c.data FROM orders o").
+		WillReturnError(sql.ErrNoRows)
 	mock.ExpectExec("INSERT INTO orders").
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(expectedError)
 
+	mock.ExpectRollback()
+
 	// Вызываем тестируемый метод с ошибкой
-	err = repo.SaveOrder(ctx, models.Order{OrderUID: "error-order"})
+	_, err = repo.SaveOrder(ctx, models.Order{OrderUID: "error-order"})
 
 	// Проверяем результаты
 	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresRepository_SaveOrderUnchanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := postgres.NewPostgresRepository(db)
+
+	order := models.Order{
+		OrderUID:    "test-order-123",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Items:       []models.Item{{ChrtID: 9934930, Price: 453}},
+	}
+	// JSONB возвращает снимок с другим форматированием и порядком ключей
+	data, err := json.Marshal(order)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	snapshot, err := json.MarshalIndent(fields, "", "  ")
+	require.NoError(t, err)
+
+	// Повторная доставка того же заказа ничего не перезаписывает
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.order_uid, c.data FROM orders o").
+		WithArgs(order.OrderUID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "data"}).AddRow(order.OrderUID, snapshot))
+	mock.ExpectRollback()
+
+	result, err := repo.SaveOrder(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderIngestDuplicate, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Без снимка заказ перезаписывается как корректировка
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.order_uid, c.data FROM orders o").
+		WillReturnRows(sqlmock.NewRows([]string{"order_uid", "data"}).AddRow(order.OrderUID, nil))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO delivery").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO payment").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM items").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO items").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO order_cache").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err = repo.SaveOrder(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderIngestUpdated, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestPostgresRepository_GetOrder(t *testing.T) {
@@ -171,7 +269,7 @@ func TestPostgresRepository_GetOrder(t *testing.T) {
 	assert.Nil(t, order)
 }
 
-func TestPostgresRepository_GetAllOrders(t *testing.T) {
+func TestPostgresRepository_GetOrders(t *testing.T) {
 	// Создаем мок для базы данных
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -183,37 +281,115 @@ func TestPostgresRepository_GetAllOrders(t *testing.T) {
 		}
 	}()
 
-	// Создаем репозиторий с моком БД
 	repo := postgres.NewPostgresRepository(db)
+	orderUIDs := []string{"order-1", "missing"}
 
-	// Настраиваем ожидание запроса
-	rows := sqlmock.NewRows([]string{"order_uid"}).
-		AddRow("order-1").
-		AddRow("order-2").
-		AddRow("order-3")
+	// Все заказы загружаются одним запросом, товары приходят JSON-массивом
+	rows := sqlmock.NewRows([]string{
+		"order_uid", "track_number", "entry", "locale", "internal_signature",
+		"customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+		"name", "phone", "zip", "city", "address", "region", "email",
+		"transaction_id", "request_id", "currency", "provider", "amount",
+		"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+		"items",
+	}).AddRow(
+		"order-1", "track123", "WBIL", "en", "",
+		"customer123", "meest", "9", 99, "2021-11-26T06:22:19Z", "1",
+		"Test User", "+7123456789", "123456", "Moscow", "123 Test St", "Test Region", "test@example.com",
+		"trans123", "", "USD", "wbpay", 1500,
+		1637907727, "sber", 200, 1300, 0,
+		`[{"chrt_id":9934930,"track_number":"track123","price":300,"rid":"rid123","name":"Test Item","sale":10,"size":"M","total_price":270,"nm_id":2389212,"brand":"Test Brand","status":202}]`,
+	)
 
-	mock.ExpectQuery("SELECT order_uid FROM orders").
+	mock.ExpectQuery("SELECT .* FROM orders o JOIN delivery d .* JOIN payment p .* WHERE o.order_uid = ANY\\(\\$1\\)").
+		WithArgs(pq.Array(orderUIDs)).
 		WillReturnRows(rows)
 
-	// Вызываем тестируемый метод
-	orders, err := repo.GetAllOrders()
+	orders, err := repo.GetOrders(context.Background(), orderUIDs)
 
-	// Проверяем результаты
 	assert.NoError(t, err)
-	assert.Len(t, orders, 3)
-	assert.Equal(t, []string{"order-1", "order-2", "order-3"}, orders)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, "order-1", orders[0].OrderUID)
+	assert.Equal(t, "USD", orders[0].Payment.Currency)
+	assert.Len(t, orders[0].Items, 1)
+	assert.Equal(t, int64(9934930), orders[0].Items[0].ChrtID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 
-	// Тестируем случай с ошибкой
-	expectedError := errors.New("database error")
-	mock.ExpectQuery("SELECT order_uid FROM orders").
-		WillReturnError(expectedError)
+	// Пустой список не обращается к БД
+	orders, err = repo.GetOrders(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
 
-	// Вызываем тестируемый метод
-	orders, err = repo.GetAllOrders()
+func TestPostgresRepository_ListOrderSnapshots(t *testing.T) {
+	// Создаем мок для базы данных
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Ошибка при создании мока БД: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			slog.Error("failed to close db", "error", err)
+		}
+	}()
 
-	// Проверяем результаты
+	repo := postgres.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	afterCreated := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"order_uid", "date_created", "data"}).
+		AddRow("order-2", afterCreated.Add(-time.Hour), []byte(`{"order_uid":"order-2"}`)).
+		AddRow("order-3", afterCreated.Add(-2*time.Hour), nil)
+
+	// Окно по дате, курсор и лимит передаются параметрами запроса; строки без даты не выбираются
+	mock.ExpectQuery("SELECT .* FROM orders o LEFT JOIN order_cache c .* WHERE o.date_created IS NOT NULL AND ").
+		WithArgs(since, afterCreated, "order-1", 2).
+		WillReturnRows(rows)
+
+	snapshots, err := repo.ListOrderSnapshots(ctx, models.OrderPageQuery{
+		Limit:        2,
+		Since:        since,
+		AfterCreated: afterCreated,
+		AfterUID:     "order-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, "order-2", snapshots[0].OrderUID)
+	assert.NotEmpty(t, snapshots[0].Data)
+	assert.Nil(t, snapshots[1].Data)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Первая страница без окна и курсора
+	mock.ExpectQuery("SELECT .* FROM orders o LEFT JOIN order_cache c").
+		WithArgs(10).
+		WillReturnError(errors.New("database error"))
+
+	snapshots, err = repo.ListOrderSnapshots(ctx, models.OrderPageQuery{Limit: 10})
+
+	assert.Error(t, err)
+	assert.Nil(t, snapshots)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnectToDB_RetriesUntilContextDone(t *testing.T) {
+	// Порт 1 закрыт: каждая попытка сразу получает отказ в соединении
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	opts := postgres.DefaultConnectOptions()
+	opts.PingTimeout = 100 * time.Millisecond
+	opts.RetryDelay = 20 * time.Millisecond
+	opts.MaxRetryDelay = 50 * time.Millisecond
+
+	start := time.Now()
+	db, err := postgres.ConnectToDB(ctx, "host=127.0.0.1 port=1 user=test dbname=test sslmode=disable", opts)
+
+	assert.Nil(t, db)
 	assert.Error(t, err)
-	assert.Nil(t, orders)
-	assert.Equal(t, expectedError, err)
+	assert.Contains(t, err.Error(), "database is not ready after")
+	assert.NotContains(t, err.Error(), "after 1 attempts")
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
 }
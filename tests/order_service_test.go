@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	"order-service/internal/infrastructure/events"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func TestOrderService_ListFallsBackToTables(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+	service := usecase.NewOrderService(mockRepo, cacheRepo)
+
+	query := models.OrderPageQuery{Limit: 3}
+	mockRepo.On("ListOrderSnapshots", mock.Anything, query).Return([]models.OrderSnapshot{
+		{OrderUID: "with-snapshot", Data: []byte(`{"order_uid":"with-snapshot"}`)},
+		{OrderUID: "without-snapshot"},
+		{OrderUID: "deleted"},
+	}, nil)
+	mockRepo.On("GetOrder", mock.Anything, "without-snapshot").Return(&models.Order{OrderUID: "without-snapshot"}, nil).Once()
+	mockRepo.On("GetOrder", mock.Anything, "deleted").Return(nil, sql.ErrNoRows).Once()
+
+	page, err := service.List(context.Background(), query)
+	require.NoError(t, err)
+
+	// Заказ, удаленный между запросами, пропускается
+	require.Len(t, page.Orders, 2)
+	assert.Equal(t, "with-snapshot", page.Orders[0].OrderUID)
+	assert.Equal(t, "without-snapshot", page.Orders[1].OrderUID)
+	assert.True(t, cacheRepo.Has("without-snapshot"))
+
+	// Курсор указывает на последнюю строку выборки, даже если сам заказ пропущен
+	require.NotNil(t, page.Next)
+	assert.Equal(t, "deleted", page.Next.UID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_ListLastPage(t *testing.T) {
+	mockRepo := new(mocks.OrderRepository)
+	service := usecase.NewOrderService(mockRepo, cache.NewCache(time.Minute))
+
+	query := models.OrderPageQuery{Limit: 3}
+	mockRepo.On("ListOrderSnapshots", mock.Anything, query).Return([]models.OrderSnapshot{
+		{OrderUID: "only", Data: []byte(`{"order_uid":"only"}`)},
+	}, nil)
+
+	page, err := service.List(context.Background(), query)
+	require.NoError(t, err)
+	assert.Len(t, page.Orders, 1)
+	assert.Nil(t, page.Next)
+}
+
+// ingestOrder возвращает заказ, проходящий проверку
+func ingestOrder() models.Order {
+	return models.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		DateCreated: time.Now().Add(-time.Hour).UTC().Truncate(time.Second),
+		Items:       []models.Item{{ChrtID: 9934930, Name: "Mascaras", Price: 453}},
+	}
+}
+
+func TestOrderService_Ingest(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+	publisher := new(mocks.InvalidationPublisher)
+	bus := events.NewBroker(10)
+	service := usecase.NewOrderService(mockRepo, cacheRepo)
+	service.SetInvalidationPublisher(publisher)
+	service.SetEventBus(bus)
+
+	received, unsubscribe := bus.Subscribe(models.OrderEventFilter{}, 0, 10)
+	defer unsubscribe()
+
+	order := ingestOrder()
+	mockRepo.On("SaveOrder", mock.Anything, order).Return(models.OrderIngestCreated, nil).Once()
+
+	result, err := service.Ingest(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderIngestCreated, result)
+	cached, found := cacheRepo.Get(order.OrderUID)
+	require.True(t, found)
+	expected, _ := json.Marshal(order)
+	assert.JSONEq(t, string(expected), string(cached))
+
+	// Повторная доставка того же заказа не доходит до БД
+	result, err = service.Ingest(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderIngestDuplicate, result)
+
+	// Корректировка перезаписывает заказ и рассылает инвалидацию
+	order.TrackNumber = "WBILMTESTTRACK2"
+	mockRepo.On("SaveOrder", mock.Anything, order).Return(models.OrderIngestUpdated, nil).Once()
+	publisher.On("PublishInvalidation", mock.Anything, models.CacheInvalidation{
+		Keys:   []string{order.OrderUID},
+		Reason: "order_updated",
+	}).Return(nil).Once()
+
+	result, err = service.Ingest(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderIngestUpdated, result)
+
+	for _, eventType := range []models.OrderEventType{models.OrderCreated, models.OrderUpdated} {
+		select {
+		case event := <-received:
+			assert.Equal(t, eventType, event.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", eventType)
+		}
+	}
+	// Снимок в БД пишет SaveOrder в своей транзакции
+	mockRepo.AssertNotCalled(t, "CacheOrderData", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestOrderService_IngestUnchangedAfterCacheEviction(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+	publisher := new(mocks.InvalidationPublisher)
+	bus := events.NewBroker(10)
+	service := usecase.NewOrderService(mockRepo, cacheRepo)
+	service.SetInvalidationPublisher(publisher)
+	service.SetEventBus(bus)
+
+	received, unsubscribe := bus.Subscribe(models.OrderEventFilter{}, 0, 10)
+	defer unsubscribe()
+
+	// Кеш заказ уже не хранит, а в БД он совпадает с доставленным
+	order := ingestOrder()
+	mockRepo.On("SaveOrder", mock.Anything, order).Return(models.OrderIngestDuplicate, nil).Once()
+
+	result, err := service.Ingest(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderIngestDuplicate, result)
+	assert.True(t, cacheRepo.Has(order.OrderUID))
+
+	// Ни инвалидации, ни события об изменении
+	select {
+	case event := <-received:
+		t.Fatalf("unexpected %s event", event.Type)
+	case <-time.After(20 * time.Millisecond):
+	}
+	mockRepo.AssertNotCalled(t, "CacheOrderData", mock.Anything, mock.Anything, mock.Anything)
+	publisher.AssertNotCalled(t, "PublishInvalidation", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_IngestRejectsInvalidOrder(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+	service := usecase.NewOrderService(mockRepo, cacheRepo)
+
+	for name, modify := range map[string]func(*models.Order){
+		"uid":          func(o *models.Order) { o.OrderUID = "bad uid" },
+		"track_number": func(o *models.Order) { o.TrackNumber = "" },
+		"items":        func(o *models.Order) { o.Items = nil },
+		"date_created": func(o *models.Order) { o.DateCreated = time.Time{} },
+	} {
+		order := ingestOrder()
+		modify(&order)
+		_, err := service.Ingest(context.Background(), order)
+		assert.ErrorIs(t, err, models.ErrInvalidOrder, name)
+	}
+
+	// Некорректный заказ не сохраняется
+	mockRepo.AssertNotCalled(t, "SaveOrder", mock.Anything, mock.Anything)
+	assert.False(t, cacheRepo.Has(ingestOrder().OrderUID))
+}
+
+func TestOrderService_IngestSaveError(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	mockRepo := new(mocks.OrderRepository)
+	service := usecase.NewOrderService(mockRepo, cacheRepo)
+
+	order := ingestOrder()
+	mockRepo.On("SaveOrder", mock.Anything, order).Return(models.IngestResult(""), errors.New("connection refused"))
+
+	_, err := service.Ingest(context.Background(), order)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, models.ErrInvalidOrder)
+	// Кеш не заполняется, чтобы повторная доставка снова попыталась сохранить заказ
+	assert.False(t, cacheRepo.Has(order.OrderUID))
+}
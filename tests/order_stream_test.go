@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	"order-service/internal/infrastructure/events"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func orderEvent(uid, currency string) models.OrderEvent {
+	return models.OrderEvent{
+		Type:  models.OrderCreated,
+		Order: models.Order{OrderUID: uid, DeliveryService: "meest", Payment: models.Payment{Currency: currency}},
+	}
+}
+
+func TestBroker_FilterAndReplay(t *testing.T) {
+	bus := events.NewBroker(2)
+
+	usd, unsubscribe := bus.Subscribe(models.OrderEventFilter{Currencies: []string{"usd"}}, 0, 4)
+	defer unsubscribe()
+
+	bus.Publish(orderEvent("order-1", "USD"))
+	bus.Publish(orderEvent("order-2", "RUB"))
+	bus.Publish(orderEvent("order-3", "USD"))
+
+	assert.Equal(t, "order-1", (<-usd).Order.OrderUID)
+	assert.Equal(t, "order-3", (<-usd).Order.OrderUID)
+
+	// История хранит два последних события; возобновление отдает те, что после afterID
+	replay, unsubscribeReplay := bus.Subscribe(models.OrderEventFilter{}, 1, 4)
+	defer unsubscribeReplay()
+	first, second := <-replay, <-replay
+	assert.Equal(t, uint64(2), first.ID)
+	assert.Equal(t, uint64(3), second.ID)
+}
+
+func TestBroker_DisconnectsSlowSubscriber(t *testing.T) {
+	bus := events.NewBroker(0)
+
+	slow, unsubscribe := bus.Subscribe(models.OrderEventFilter{}, 0, 1)
+	defer unsubscribe()
+
+	bus.Publish(orderEvent("order-1", "USD"))
+	bus.Publish(orderEvent("order-2", "USD"))
+
+	// Первое событие доставлено, на втором буфер переполнился и канал закрыт
+	_, ok := <-slow
+	assert.True(t, ok)
+	_, ok = <-slow
+	assert.False(t, ok)
+}
+
+func newStreamTestServer(t *testing.T, bus *events.Broker) *httptest.Server {
+	cacheRepo := cache.NewCache(time.Minute)
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetEventBus(bus)
+
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// readSSEEvent читает одно событие SSE, пропуская служебные строки
+func readSSEEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	t.Helper()
+
+	fields := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if _, ok := fields["data"]; ok {
+				return fields
+			}
+			continue
+		}
+		if name, value, ok := strings.Cut(line, ": "); ok && !strings.HasPrefix(line, ":") {
+			fields[name] = value
+		}
+	}
+}
+
+func TestOrderStream_SSE(t *testing.T) {
+	bus := events.NewBroker(16)
+	ts := newStreamTestServer(t, bus)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/orders/stream?currency=USD", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Подписка оформляется до отправки заголовков ответа
+	bus.Publish(orderEvent("order-rub", "RUB"))
+	bus.Publish(orderEvent("order-usd", "USD"))
+
+	event := readSSEEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "2", event["id"])
+	assert.Equal(t, "created", event["event"])
+	assert.Contains(t, event["data"], `"order_uid":"order-usd"`)
+}
+
+func TestOrderStream_SSEResume(t *testing.T) {
+	bus := events.NewBroker(16)
+	ts := newStreamTestServer(t, bus)
+
+	bus.Publish(orderEvent("order-1", "USD"))
+	bus.Publish(orderEvent("order-2", "USD"))
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/orders/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	event := readSSEEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, "2", event["id"])
+	assert.Contains(t, event["data"], `"order_uid":"order-2"`)
+
+	badReq, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/orders/stream", nil)
+	badReq.Header.Set("Last-Event-ID", "abc")
+	badResp, err := http.DefaultClient.Do(badReq)
+	require.NoError(t, err)
+	badResp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, badResp.StatusCode)
+}
+
+func TestOrderStream_WebSocket(t *testing.T) {
+	bus := events.NewBroker(16)
+	ts := newStreamTestServer(t, bus)
+
+	bus.Publish(orderEvent("order-1", "USD"))
+	bus.Publish(orderEvent("order-2", "RUB"))
+	bus.Publish(orderEvent("order-3", "USD"))
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/orders/stream?currency=USD&last_event_id=1"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event models.OrderEvent
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, uint64(3), event.ID)
+	assert.Equal(t, "order-3", event.Order.OrderUID)
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+func TestPII_MaskFunctions(t *testing.T) {
+	assert.Equal(t, "+7******1234", models.MaskPhone("+7 (999) 123-1234"))
+	assert.Equal(t, "+9*****0000", models.MaskPhone("+9720000000"))
+	assert.Equal(t, "***", models.MaskPhone("123"))
+	assert.Equal(t, "u***@example.com", models.MaskEmail("user@example.com"))
+	assert.Equal(t, "T*** T***", models.MaskWords("Test Testov"))
+	assert.Equal(t, "26*****", models.MaskZip("2639809"))
+}
+
+func TestPII_PolicyByRole(t *testing.T) {
+	order := models.Order{OrderUID: "order-1", Delivery: models.Delivery{
+		Name: "Test Testov", Phone: "+9720000000", City: "Kiryat Mozkin", Email: "test@gmail.com",
+	}}
+	policy := models.DefaultPIIPolicy()
+
+	masked := policy.Apply(&models.Principal{Subject: "support-ui", Roles: []models.Role{models.RoleSupport}}, order)
+	assert.Equal(t, "t***@gmail.com", masked.Delivery.Email)
+	assert.Equal(t, "Kiryat Mozkin", masked.Delivery.City)
+	// Исходный заказ не меняется
+	assert.Equal(t, "test@gmail.com", order.Delivery.Email)
+
+	assert.Equal(t, "T*** T***", policy.Apply(nil, order).Delivery.Name)
+	assert.Equal(t, order, policy.Apply(&models.Principal{Roles: []models.Role{models.RoleAdmin}}, order))
+}
+
+func TestPII_MaskedInResponses(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order","delivery":{"name":"Test Testov","phone":"+9720000000","email":"test@gmail.com"}}`), 0)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	handler := server.Handler()
+
+	getAs := func(key, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var order models.Order
+	rec := getAs(testSupportKey, "/api/v1/orders/test-order")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	assert.Equal(t, "+9*****0000", order.Delivery.Phone)
+	assert.Equal(t, "t***@gmail.com", order.Delivery.Email)
+
+	rec = getAs(testAdminToken, "/api/v1/orders/test-order")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &order))
+	assert.Equal(t, "+9720000000", order.Delivery.Phone)
+
+	rec = httptest.NewRecorder()
+	req := batchGetRequest(`{"order_uids":["test-order"]}`)
+	req.Header.Set("X-API-Key", testServiceKey)
+	handler.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Body.String(), `"phone":"+9*****0000"`)
+	assert.NotContains(t, rec.Body.String(), "test@gmail.com")
+}
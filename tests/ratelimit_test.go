@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"order-service/internal/domain/models"
+	"order-service/internal/infrastructure/cache"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/usecase"
+	"order-service/mocks"
+)
+
+// Пополнение настолько медленное, что за время теста корзина не наполняется
+const slowRate = 0.001
+
+func newRateLimitTestServer(t *testing.T, limits orderhttp.RateLimitConfig) (*mocks.OrderRepository, http.Handler) {
+	cacheRepo := cache.NewCache(time.Minute)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order"}`), 0)
+	mockRepo := new(mocks.OrderRepository)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(mockRepo, cacheRepo), cacheRepo)
+	server.SetRateLimits(limits)
+	return mockRepo, server.Handler()
+}
+
+func requestFrom(handler http.Handler, target, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = remoteAddr
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_PerIP(t *testing.T) {
+	_, handler := newRateLimitTestServer(t, orderhttp.RateLimitConfig{
+		PerIP: orderhttp.RateLimit{Rate: slowRate, Burst: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.1:1234", nil).Code)
+	}
+
+	rec := requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.1:1234", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, "rate_limited", decodeProblem(t, rec).Code)
+
+	// Другой клиент и другой маршрут считаются отдельно
+	assert.Equal(t, http.StatusOK, requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.2:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, requestFrom(handler, "/health", "198.51.100.1:1234", nil).Code)
+}
+
+func TestRateLimit_ReloadKeepsBuckets(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order"}`), 0)
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	limits := orderhttp.RateLimitConfig{PerIP: orderhttp.RateLimit{Rate: slowRate, Burst: 2}}
+	server.SetRateLimits(limits)
+	handler := server.Handler()
+
+	request := func() int {
+		return requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.1:1234", nil).Code
+	}
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, request())
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request())
+
+	// Перезагрузка с теми же ограничениями не наполняет корзину заново
+	server.SetRateLimits(orderhttp.RateLimitConfig{PerIP: orderhttp.RateLimit{Rate: slowRate, Burst: 2}})
+	assert.Equal(t, http.StatusTooManyRequests, request())
+
+	// Большая емкость не дает маркеров сразу, а пополняется со временем
+	server.SetRateLimits(orderhttp.RateLimitConfig{PerIP: orderhttp.RateLimit{Rate: slowRate, Burst: 5}})
+	assert.Equal(t, http.StatusTooManyRequests, request())
+
+	// Отключенное ограничение сбрасывает корзину
+	server.SetRateLimits(orderhttp.RateLimitConfig{})
+	assert.Equal(t, http.StatusOK, request())
+	server.SetRateLimits(limits)
+	assert.Equal(t, http.StatusOK, request())
+}
+
+func TestRateLimit_TrustedProxies(t *testing.T) {
+	proxies, err := orderhttp.ParseTrustedProxies("10.0.0.0/8, 192.0.2.10")
+	require.NoError(t, err)
+	_, handler := newRateLimitTestServer(t, orderhttp.RateLimitConfig{
+		PerIP:          orderhttp.RateLimit{Rate: slowRate, Burst: 1},
+		TrustedProxies: proxies,
+	})
+
+	// За доверенными прокси клиенты различаются по X-Forwarded-For
+	viaProxy := func(xff string) int {
+		return requestFrom(handler, "/api/v1/orders/test-order", "10.1.2.3:443",
+			http.Header{"X-Forwarded-For": {xff}}).Code
+	}
+	assert.Equal(t, http.StatusOK, viaProxy("203.0.113.5, 192.0.2.10"))
+	assert.Equal(t, http.StatusTooManyRequests, viaProxy("203.0.113.5"))
+	assert.Equal(t, http.StatusOK, viaProxy("203.0.113.6"))
+
+	// Недоверенный клиент не может подменить адрес через заголовок
+	direct := func(xff string) int {
+		return requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.7:1234",
+			http.Header{"X-Forwarded-For": {xff}}).Code
+	}
+	assert.Equal(t, http.StatusOK, direct("203.0.113.100"))
+	assert.Equal(t, http.StatusTooManyRequests, direct("203.0.113.101"))
+}
+
+func TestRateLimit_PerKeyAndRoute(t *testing.T) {
+	cacheRepo := cache.NewCache(time.Minute)
+	cacheRepo.Set("test-order", []byte(`{"order_uid":"test-order"}`), 0)
+
+	server := orderhttp.NewOrderHTTPServer(0, usecase.NewOrderService(new(mocks.OrderRepository), cacheRepo), cacheRepo)
+	server.SetAuthenticator(newTestAuthenticator(t))
+	server.SetRateLimits(orderhttp.RateLimitConfig{
+		PerKey: orderhttp.RateLimit{Rate: slowRate, Burst: 1},
+		PerIP:  orderhttp.RateLimit{Rate: slowRate, Burst: 1},
+		Routes: map[string]orderhttp.RateLimit{"GET /orders/{uid}": {Rate: slowRate, Burst: 3}},
+	})
+	handler := server.Handler()
+
+	withKey := func(target, key string) int {
+		return requestFrom(handler, target, "198.51.100.1:1234", http.Header{"X-Api-Key": {key}}).Code
+	}
+
+	// У каждого ключа своя корзина, даже с одного адреса
+	assert.Equal(t, http.StatusOK, withKey("/api/v1/orders/test-order", testSupportKey))
+	assert.Equal(t, http.StatusTooManyRequests, withKey("/api/v1/orders/test-order", testSupportKey))
+	assert.Equal(t, http.StatusOK, withKey("/api/v1/orders/test-order", testServiceKey))
+
+	// Для HTML-страницы задан собственный лимит
+	for i := 0; i < 3; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, withKey("/orders/test-order", testSupportKey))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, withKey("/orders/test-order", testSupportKey))
+}
+
+func TestRateLimit_ConcurrencyLimit(t *testing.T) {
+	mockRepo, handler := newRateLimitTestServer(t, orderhttp.RateLimitConfig{MaxConcurrent: 1})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mockRepo.On("GetOrder", mock.Anything, "slow-order").Run(func(mock.Arguments) {
+		close(started)
+		<-release
+	}).Return(&models.Order{OrderUID: "slow-order"}, nil).Once()
+
+	done := make(chan int)
+	go func() {
+		done <- requestFrom(handler, "/api/v1/orders/slow-order", "198.51.100.1:1234", nil).Code
+	}()
+	<-started
+
+	rec := requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.2:1234", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "overloaded", decodeProblem(t, rec).Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.2:1234", nil).Code)
+}
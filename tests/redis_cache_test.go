@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"order-service/internal/infrastructure/cache"
+)
+
+func newTestRedisCache(t *testing.T, ttl time.Duration) (*miniredis.Miniredis, *cache.RedisCache) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return mr, cache.NewRedisCache(client, "order:", ttl, time.Second)
+}
+
+func TestRedisCache_SetGetDelete(t *testing.T) {
+	mr, redisCache := newTestRedisCache(t, time.Minute)
+
+	redisCache.Set("order-1", []byte(`{"order_uid":"order-1"}`), 0)
+
+	// Ключ хранится с префиксом и TTL
+	assert.True(t, mr.Exists("order:order-1"))
+	assert.Equal(t, time.Minute, mr.TTL("order:order-1"))
+
+	data, found := redisCache.Get("order-1")
+	assert.True(t, found)
+	assert.JSONEq(t, `{"order_uid":"order-1"}`, string(data))
+	assert.True(t, redisCache.Has("order-1"))
+
+	_, found = redisCache.Get("missing")
+	assert.False(t, found)
+
+	redisCache.Delete("order-1")
+	assert.False(t, redisCache.Has("order-1"))
+
+	stats := redisCache.Stats()
+	assert.Equal(t, "redis", stats.Backend)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 0.5, stats.HitRatio)
+}
+
+func TestRedisCache_Expiry(t *testing.T) {
+	mr, redisCache := newTestRedisCache(t, time.Minute)
+
+	redisCache.Set("order-1", []byte(`{}`), 0)
+	mr.FastForward(2 * time.Minute)
+
+	_, found := redisCache.Get("order-1")
+	assert.False(t, found)
+}
+
+func TestRedisCache_ZeroTTLKeepsKey(t *testing.T) {
+	mr, redisCache := newTestRedisCache(t, 0)
+
+	redisCache.Set("order-1", []byte(`{}`), 0)
+
+	// Без TTL по умолчанию ключ хранится бессрочно, а не удаляется
+	assert.True(t, mr.Exists("order:order-1"))
+	assert.Equal(t, time.Duration(0), mr.TTL("order:order-1"))
+
+	mr.FastForward(24 * time.Hour)
+	data, found := redisCache.Get("order-1")
+	assert.True(t, found)
+	assert.Equal(t, `{}`, string(data))
+}
+
+func TestRedisCache_Unavailable(t *testing.T) {
+	mr, redisCache := newTestRedisCache(t, time.Minute)
+	mr.Close()
+
+	// Недоступный Redis не роняет вызывающий код и считается промахом
+	redisCache.Set("order-1", []byte(`{}`), 0)
+	_, found := redisCache.Get("order-1")
+	assert.False(t, found)
+	assert.False(t, redisCache.Has("order-1"))
+}
+
+func TestTieredCache(t *testing.T) {
+	_, remote := newTestRedisCache(t, time.Minute)
+	local := cache.NewCache(time.Minute)
+	tiered := cache.NewTieredCache(local, remote, time.Minute)
+
+	// Запись другой реплики попадает только в общий кэш
+	remote.Set("order-1", []byte(`{"order_uid":"order-1"}`), 0)
+	assert.False(t, local.Has("order-1"))
+
+	// Чтение через двухуровневый кэш подтягивает значение в локальный уровень
+	data, found := tiered.Get("order-1")
+	assert.True(t, found)
+	assert.JSONEq(t, `{"order_uid":"order-1"}`, string(data))
+	assert.True(t, local.Has("order-1"))
+
+	tiered.Set("order-2", []byte(`{}`), 0)
+	assert.True(t, local.Has("order-2"))
+	assert.True(t, remote.Has("order-2"))
+
+	tiered.Delete("order-2")
+	assert.False(t, local.Has("order-2"))
+	assert.False(t, remote.Has("order-2"))
+
+	_, found = tiered.Get("missing")
+	assert.False(t, found)
+
+	stats := tiered.Stats()
+	assert.Equal(t, "tiered", stats.Backend)
+	assert.Len(t, stats.Layers, 2)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+}
+
+func TestTieredCache_PromotionKeepsRemoteTTL(t *testing.T) {
+	_, remote := newTestRedisCache(t, time.Hour)
+	local := cache.NewCache(time.Hour)
+	tiered := cache.NewTieredCache(local, remote, 10*time.Minute)
+
+	// Свежий заказ живет в общем кэше недолго — локальная копия не должна его пережить
+	remote.Set("order-1", []byte(`{}`), 30*time.Second)
+	_, found := tiered.Get("order-1")
+	assert.True(t, found)
+
+	info, found := local.Inspect("order-1")
+	assert.True(t, found)
+	assert.LessOrEqual(t, info.TTL, 30*time.Second)
+
+	// Долгоживущий элемент ограничивается localTTL
+	remote.Set("order-2", []byte(`{}`), 0)
+	_, found = tiered.Get("order-2")
+	assert.True(t, found)
+
+	info, found = local.Inspect("order-2")
+	assert.True(t, found)
+	assert.LessOrEqual(t, info.TTL, 10*time.Minute)
+	assert.Greater(t, info.TTL, 9*time.Minute)
+}
+
+func TestRedisCache_InspectAndDeletePrefix(t *testing.T) {
+	mr, redisCache := newTestRedisCache(t, time.Minute)
+
+	before := time.Now().Truncate(time.Millisecond)
+	redisCache.Set("test-1", []byte(`{"a":1}`), 0)
+	redisCache.Set("test-2", []byte(`{}`), 0)
+	redisCache.Set("order-1", []byte(`{}`), 0)
+	// Элемент с собственным TTL длиннее TTL по умолчанию
+	redisCache.Set("long", []byte(`{}`), time.Hour)
+	mr.FastForward(10 * time.Second)
+
+	info, found := redisCache.Inspect("test-1")
+	assert.True(t, found)
+	assert.Equal(t, 7, info.Size)
+	assert.Equal(t, 50*time.Second, info.TTL)
+	assert.False(t, info.CreatedAt.Before(before))
+	assert.False(t, info.CreatedAt.After(time.Now()))
+
+	// Возраст считается от времени записи, а не от TTL по умолчанию
+	info, found = redisCache.Inspect("long")
+	assert.True(t, found)
+	assert.Equal(t, time.Hour-10*time.Second, info.TTL)
+	assert.GreaterOrEqual(t, info.Age, time.Duration(0))
+	assert.False(t, info.CreatedAt.After(time.Now()))
+
+	_, found = redisCache.Inspect("missing")
+	assert.False(t, found)
+
+	assert.Equal(t, 2, redisCache.DeletePrefix("test-"))
+	redisCache.Delete("long")
+	assert.False(t, redisCache.Has("test-1"))
+	assert.True(t, redisCache.Has("order-1"))
+
+	// Flush не трогает ключи без префикса кэша
+	mr.Set("foreign", "value")
+	redisCache.Flush()
+	assert.False(t, redisCache.Has("order-1"))
+	assert.True(t, mr.Exists("foreign"))
+}
+
+func TestRedisCache_DeletePrefixIsLiteral(t *testing.T) {
+	_, redisCache := newTestRedisCache(t, time.Minute)
+
+	redisCache.Set("test*1", []byte(`{}`), 0)
+	redisCache.Set("test-1", []byte(`{}`), 0)
+	redisCache.Set("tes?", []byte(`{}`), 0)
+	redisCache.Set("test[1]", []byte(`{}`), 0)
+
+	// Символы шаблона в префиксе не расширяют удаление на чужие ключи
+	assert.Equal(t, 1, redisCache.DeletePrefix("test*"))
+	assert.False(t, redisCache.Has("test*1"))
+	assert.True(t, redisCache.Has("test-1"))
+
+	assert.Equal(t, 0, redisCache.DeletePrefix("tes?1"))
+	assert.Equal(t, 1, redisCache.DeletePrefix("test["))
+	assert.True(t, redisCache.Has("test-1"))
+	assert.True(t, redisCache.Has("tes?"))
+}
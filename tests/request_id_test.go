@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order-service/internal/domain/models"
+	orderhttp "order-service/internal/infrastructure/http"
+	"order-service/internal/logger"
+	"order-service/mocks"
+	orderv1 "order-service/pkg/api/order/v1"
+)
+
+// captureLogs направляет журнал в буфер на время теста и возвращает разобранные записи
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logger.NewContextHandler(slog.NewJSONHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var record map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestContextHandler_AttachesContextAttrs(t *testing.T) {
+	records := captureLogs(t)
+
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	ctx = logger.WithAttrs(ctx, slog.Int("partition", 3), slog.Int64("offset", 42))
+	slog.With("component", "test").InfoContext(ctx, "with context")
+	slog.Info("without context")
+
+	logged := records()
+	require.Len(t, logged, 2)
+	assert.Equal(t, "req-1", logged[0]["request_id"])
+	assert.Equal(t, float64(3), logged[0]["partition"])
+	assert.Equal(t, float64(42), logged[0]["offset"])
+	assert.Equal(t, "test", logged[0]["component"])
+	assert.NotContains(t, logged[1], "request_id")
+
+	assert.Equal(t, "req-1", logger.RequestIDFromContext(ctx))
+}
+
+func TestRequestID_HTTP(t *testing.T) {
+	_, handler := newRateLimitTestServer(t, orderhttp.RateLimitConfig{})
+	records := captureLogs(t)
+
+	// Допустимый идентификатор клиента возвращается как есть и попадает в журнал
+	rec := requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.1:1234",
+		http.Header{"X-Request-Id": {"client-req.42"}})
+	assert.Equal(t, "client-req.42", rec.Header().Get("X-Request-ID"))
+
+	var found bool
+	for _, record := range records() {
+		if record["msg"] == "HTTP request" {
+			found = true
+			assert.Equal(t, "client-req.42", record["request_id"])
+		}
+	}
+	assert.True(t, found, "request log record not found")
+
+	// Без заголовка или с недопустимым значением создается новый идентификатор
+	for _, header := range []http.Header{nil, {"X-Request-Id": {"bad id\nforged"}}, {"X-Request-Id": {strings.Repeat("a", 129)}}} {
+		rec := requestFrom(handler, "/health", "198.51.100.1:1234", header)
+		_, err := uuid.Parse(rec.Header().Get("X-Request-ID"))
+		assert.NoError(t, err)
+	}
+}
+
+func TestRequestID_GRPC(t *testing.T) {
+	mockOrders := new(mocks.OrderService)
+	client := orderv1.NewOrderServiceClient(newGRPCTestClient(t, mockOrders, nil))
+
+	var seen string
+	mockOrders.On("Get", mock.Anything, "test-order").Run(func(args mock.Arguments) {
+		seen = logger.RequestIDFromContext(args.Get(0).(context.Context))
+	}).Return(&models.Order{OrderUID: "test-order"}, nil)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "grpc-req-1")
+	var header metadata.MD
+	_, err := client.GetOrder(ctx, &orderv1.GetOrderRequest{OrderUid: "test-order"}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Equal(t, "grpc-req-1", seen)
+	assert.Equal(t, []string{"grpc-req-1"}, header.Get("x-request-id"))
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-service/internal/domain/models"
+	orderhttp "order-service/internal/infrastructure/http"
+	orderkafka "order-service/internal/infrastructure/kafka"
+	"order-service/internal/infrastructure/postgres"
+)
+
+// Входящий traceparent из примера спецификации W3C Trace Context
+const (
+	testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+// recordSpans подменяет глобальный TracerProvider на время теста и возвращает записанные span'ы
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+func TestTracing_HTTPContinuesIncomingTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	_, handler := newRateLimitTestServer(t, orderhttp.RateLimitConfig{})
+	records := captureLogs(t)
+
+	rec := requestFrom(handler, "/api/v1/orders/test-order", "198.51.100.1:1234",
+		http.Header{"Traceparent": {testTraceParent}})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := recorder.Ended()
+	assert.ElementsMatch(t, []string{"GET /api/v1/orders/{uid}", "cache get"}, spanNames(spans))
+	for _, span := range spans {
+		assert.Equal(t, testTraceID, span.SpanContext().TraceID().String(), span.Name())
+		if span.SpanKind() == trace.SpanKindServer {
+			assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+		}
+	}
+
+	// Журнал запроса связан с трассировкой
+	var found bool
+	for _, record := range records() {
+		if record["msg"] == "HTTP request" {
+			found = true
+			assert.Equal(t, testTraceID, record["trace_id"])
+		}
+	}
+	assert.True(t, found, "request log record not found")
+}
+
+func TestTracing_KafkaHeadersLinkProducerAndConsumer(t *testing.T) {
+	recorder := recordSpans(t)
+
+	msg := kafka.Message{Headers: []kafka.Header{{Key: "traceparent", Value: []byte("stale")}}}
+	_, span := orderkafka.StartProducerSpan(context.Background(), "orders", &msg)
+	span.End()
+
+	// Старый заголовок заменяется, а не дублируется
+	require.Len(t, msg.Headers, 1)
+
+	extracted := otel.GetTextMapPropagator().Extract(context.Background(), orderkafka.NewHeaderCarrier(&msg))
+	remote := trace.SpanContextFromContext(extracted)
+	produced := recorder.Ended()[0]
+
+	assert.Equal(t, "publish orders", produced.Name())
+	assert.Equal(t, produced.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, produced.SpanContext().SpanID(), remote.SpanID())
+}
+
+func TestTracing_RepositoryQueries(t *testing.T) {
+	recorder := recordSpans(t)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := postgres.NewPostgresRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT o.order_uid, c.data FROM orders o").WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO delivery").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO payment").WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	_, err = repo.SaveOrder(context.Background(), models.Order{OrderUID: "test-order"})
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	spans := recorder.Ended()
+	assert.Equal(t, []string{"SELECT orders", "INSERT orders", "INSERT delivery", "INSERT payment", "PostgresRepository.SaveOrder"}, spanNames(spans))
+
+	// Отсутствие заказа не ошибка, а сбой вставки помечается и в запросе, и в методе
+	statuses := make(map[string]string, len(spans))
+	for _, span := range spans {
+		statuses[span.Name()] = span.Status().Code.String()
+	}
+	assert.Equal(t, "Unset", statuses["SELECT orders"])
+	assert.Equal(t, "Error", statuses["INSERT payment"])
+	assert.Equal(t, "Error", statuses["PostgresRepository.SaveOrder"])
+}